@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"strconv"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// ApiKeyHandler: Structure used to store an apiKeyService object
+type ApiKeyHandler struct {
+	apiKeyService interfaces.IApiKeyService
+}
+
+// NewApiKeyController: Receives a reference to the apiKeyService interface and stores it in the ApiKeyHandler structure
+// Params:
+// (apiKeyService): Reference to an apiKeyService interface
+// Return:
+// (*ApiKeyHandler): Reference to the ApiKeyHandler object
+func NewApiKeyController(apiKeyService interfaces.IApiKeyService) *ApiKeyHandler {
+	return &ApiKeyHandler{apiKeyService: apiKeyService}
+}
+
+// ResponseCreateKey: Handles the POST request that gets at the endpoint /api/v1/keys.
+// Receives a JSON body with the name and scopes of the key. The key is only returned in this response
+// Params:
+// (ctx): Request reference
+func (h *ApiKeyHandler) ResponseCreateKey(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.apiKeyService.WithOrg(orgID(ctx)).CreateKey(ctx.PostBody())
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(201)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseKeys: Handles the GET request that gets at the endpoint /api/v1/keys
+// Params:
+// (ctx): Request reference
+func (h *ApiKeyHandler) ResponseKeys(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.apiKeyService.WithOrg(orgID(ctx)).GetKeys()
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseDeleteKey: Handles the DELETE request that gets at the endpoint /api/v1/keys/:id
+// Params:
+// (ctx): Request reference
+func (h *ApiKeyHandler) ResponseDeleteKey(ctx *fasthttp.RequestCtx) {
+	id, parseErr := strconv.ParseInt(ctx.UserValue("id").(string), 10, 64)
+	if parseErr != nil {
+		raiseError(ctx, 400, "Invalid API key id")
+		return
+	}
+	if err := h.apiKeyService.WithOrg(orgID(ctx)).DeleteKey(id); err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetStatusCode(204)
+	}
+}
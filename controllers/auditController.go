@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/JonatanOrdonez/tr-backend/models"
+	"github.com/valyala/fasthttp"
+)
+
+// AuditHandler: Structure used to store an auditService object
+type AuditHandler struct {
+	auditService interfaces.IAuditService
+	logger       interfaces.ILogger
+}
+
+// NewAuditController: Receives a reference to the auditService interface and stores it in the AuditHandler structure
+// Params:
+// (auditService): Reference to an auditService interface
+// (logger): Reference to the logger
+// Return:
+// (*AuditHandler): Reference to the AuditHandler object
+func NewAuditController(auditService interfaces.IAuditService, logger interfaces.ILogger) *AuditHandler {
+	return &AuditHandler{auditService: auditService, logger: logger}
+}
+
+// ResponseAudit: Handles the request that gets at the endpoint /api/v1/audit.
+// Returns the latest events of the audit log, filtered by the actor and since query params
+// Params:
+// (ctx): Request reference
+func (h *AuditHandler) ResponseAudit(ctx *fasthttp.RequestCtx) {
+	actor := string(ctx.QueryArgs().Peek("actor"))
+	since := string(ctx.QueryArgs().Peek("since"))
+	jsonBody, err := h.auditService.WithOrg(orgID(ctx)).GetEvents(actor, since)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// Record: Middleware that appends the request to the audit log once it is handled, with its outcome
+// Params:
+// (action): Name of the action, such as webhook.create
+// (next): Handler of the request
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func (h *AuditHandler) Record(action string, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		next(ctx)
+		h.record(ctx, action)
+	}
+}
+
+// Analyze: Middleware of /api/v1/analyze, which only changes the data when the host query param launches a scan
+// Params:
+// (next): Handler of the request
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func (h *AuditHandler) Analyze(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		next(ctx)
+		if len(ctx.QueryArgs().Peek("host")) > 0 {
+			h.record(ctx, "domain.analyze")
+		}
+	}
+}
+
+// record: Builds the event of a handled request and stores it
+// Params:
+// (ctx): Request reference
+// (action): Name of the action
+func (h *AuditHandler) record(ctx *fasthttp.RequestCtx, action string) {
+	event := &models.AuditEvent{Action: action, Method: string(ctx.Method()), Path: string(ctx.Path()), RequestId: requestID(ctx), StatusCode: ctx.Response.StatusCode(), Outcome: models.AuditSuccess, OccurredAt: time.Now().Unix()}
+	if principal := GetPrincipal(ctx); principal != nil {
+		event.Actor = principal.Subject
+	}
+	if host, ok := ctx.UserValue("host").(string); ok {
+		event.Host = host
+	} else {
+		event.Host = string(ctx.QueryArgs().Peek("host"))
+	}
+	if event.StatusCode >= 400 {
+		event.Outcome = models.AuditFailure
+	}
+	if err := h.auditService.WithOrg(orgID(ctx)).Record(event); err != nil {
+		h.logger.Error(requestContext(ctx), "Audit event cannot be stored", "action", action, "error", err)
+	}
+}
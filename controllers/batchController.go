@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	models "github.com/JonatanOrdonez/tr-backend/models"
+	"github.com/valyala/fasthttp"
+)
+
+// BatchHandler: Structure used to store a batchService object
+type BatchHandler struct {
+	batchService interfaces.IBatchService
+}
+
+// NewBatchController: Receives a reference to the batchService interface and stores it in the BatchHandler structure
+// Params:
+// (batchService): Reference to a batchService interface
+// Return:
+// (*BatchHandler): Reference to the BatchHandler object
+func NewBatchController(batchService interfaces.IBatchService) *BatchHandler {
+	return &BatchHandler{batchService: batchService}
+}
+
+// ResponseCreateBatch: Handles the POST request that gets at the endpoint /api/v1/analyze/bulk.
+// Receives a JSON array of hosts, a newline-delimited list or a CSV file uploaded in the "file" field, and queues them
+// Params:
+// (ctx): Request reference
+func (h *BatchHandler) ResponseCreateBatch(ctx *fasthttp.RequestCtx) {
+	content, format := ctx.PostBody(), models.BulkText
+	contentType := strings.ToLower(string(ctx.Request.Header.ContentType()))
+	switch {
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		fileHeader, formErr := ctx.FormFile("file")
+		if formErr != nil {
+			raiseError(ctx, 400, "File is required")
+			return
+		}
+		file, openErr := fileHeader.Open()
+		if openErr != nil {
+			raiseError(ctx, 400, "File cannot be read")
+			return
+		}
+		defer file.Close()
+		fileContent, readErr := ioutil.ReadAll(file)
+		if readErr != nil {
+			raiseError(ctx, 400, "File cannot be read")
+			return
+		}
+		content, format = fileContent, models.BulkCSV
+	case strings.HasPrefix(contentType, "application/json"):
+		format = models.BulkJSON
+	case strings.HasPrefix(contentType, "text/csv"):
+		format = models.BulkCSV
+	}
+	jsonBody, err := h.batchService.WithOrg(orgID(ctx)).CreateBatch(content, format)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(202)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseBatch: Handles the request that gets at the endpoint /api/v1/batches/:id.
+// Returns the progress of each host of the batch
+// Params:
+// (ctx): Request reference
+func (h *BatchHandler) ResponseBatch(ctx *fasthttp.RequestCtx) {
+	idParam, _ := ctx.UserValue("id").(string)
+	id, parseErr := strconv.ParseInt(idParam, 10, 64)
+	if parseErr != nil {
+		raiseError(ctx, 400, "Invalid id")
+		return
+	}
+	jsonBody, err := h.batchService.WithOrg(orgID(ctx)).GetBatch(id)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"strconv"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// CertificateHandler: Structure used to store a certificateService object
+type CertificateHandler struct {
+	certificateService interfaces.ICertificateService
+}
+
+// NewCertificateController: Receives a reference to the certificateService interface and stores it in the CertificateHandler structure
+// Params:
+// (certificateService): Reference to a certificateService interface
+// Return:
+// (*CertificateHandler): Reference to the CertificateHandler object
+func NewCertificateController(certificateService interfaces.ICertificateService) *CertificateHandler {
+	return &CertificateHandler{certificateService: certificateService}
+}
+
+// ResponseExpiring: Handles the request that gets at the endpoint /api/v1/certificates/expiring.
+// Returns the certificates that expire within the days query param (30 by default)
+// Params:
+// (ctx): Request reference
+func (h *CertificateHandler) ResponseExpiring(ctx *fasthttp.RequestCtx) {
+	days := 30
+	if daysParam := string(ctx.QueryArgs().Peek("days")); daysParam != "" {
+		parsedDays, parseErr := strconv.Atoi(daysParam)
+		if parseErr != nil {
+			raiseError(ctx, 400, "Invalid days")
+			return
+		}
+		days = parsedDays
+	}
+	jsonBody, err := h.certificateService.WithOrg(orgID(ctx)).GetExpiring(days)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"strconv"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// ChannelHandler: Structure used to store a notificationService object
+type ChannelHandler struct {
+	notificationService interfaces.INotificationService
+}
+
+// NewChannelController: Receives a reference to the notificationService interface and stores it in the ChannelHandler structure
+// Params:
+// (notificationService): Reference to a notificationService interface
+// Return:
+// (*ChannelHandler): Reference to the ChannelHandler object
+func NewChannelController(notificationService interfaces.INotificationService) *ChannelHandler {
+	return &ChannelHandler{notificationService: notificationService}
+}
+
+// ResponseCreateChannel: Handles the POST request that gets at the endpoint /api/v1/channels.
+// Receives a JSON body with the name, type (email, slack or teams), target, events and hosts of the channel
+// Params:
+// (ctx): Request reference
+func (h *ChannelHandler) ResponseCreateChannel(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.notificationService.WithOrg(orgID(ctx)).CreateChannel(ctx.PostBody())
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(201)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseChannels: Handles the GET request that gets at the endpoint /api/v1/channels
+// Params:
+// (ctx): Request reference
+func (h *ChannelHandler) ResponseChannels(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.notificationService.WithOrg(orgID(ctx)).GetChannels()
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseDeleteChannel: Handles the DELETE request that gets at the endpoint /api/v1/channels/:id
+// Params:
+// (ctx): Request reference
+func (h *ChannelHandler) ResponseDeleteChannel(ctx *fasthttp.RequestCtx) {
+	id, parseErr := strconv.ParseInt(ctx.UserValue("id").(string), 10, 64)
+	if parseErr != nil {
+		raiseError(ctx, 400, "Invalid channel id")
+		return
+	}
+	if err := h.notificationService.WithOrg(orgID(ctx)).DeleteChannel(id); err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetStatusCode(204)
+	}
+}
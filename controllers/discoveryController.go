@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// DiscoveryHandler: Structure used to store a discoveryService object
+type DiscoveryHandler struct {
+	discoveryService interfaces.IDiscoveryService
+}
+
+// NewDiscoveryController: Receives a reference to the discoveryService interface and stores it in the DiscoveryHandler structure
+// Params:
+// (discoveryService): Reference to a discoveryService interface
+// Return:
+// (*DiscoveryHandler): Reference to the DiscoveryHandler object
+func NewDiscoveryController(discoveryService interfaces.IDiscoveryService) *DiscoveryHandler {
+	return &DiscoveryHandler{discoveryService: discoveryService}
+}
+
+// ResponseDiscovered: Handles the request that gets at the endpoint /api/v1/domains/:host/discovered.
+// Returns the hosts found in the certificates of the domain
+// Params:
+// (ctx): Request reference
+func (h *DiscoveryHandler) ResponseDiscovered(ctx *fasthttp.RequestCtx) {
+	hostPath, _ := ctx.UserValue("host").(string)
+	jsonBody, err := h.discoveryService.WithOrg(orgID(ctx)).GetDiscovered(hostPath)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseTrack: Handles the POST request that gets at the endpoint /api/v1/domains/:host/discovered/:name/track.
+// Analyzes the discovered host and returns it as a tracked domain
+// Params:
+// (ctx): Request reference
+func (h *DiscoveryHandler) ResponseTrack(ctx *fasthttp.RequestCtx) {
+	hostPath, _ := ctx.UserValue("host").(string)
+	name, _ := ctx.UserValue("name").(string)
+	jsonBody, err := h.discoveryService.WithOrg(orgID(ctx)).TrackDiscovered(hostPath, name)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(201)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
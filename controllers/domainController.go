@@ -1,37 +1,46 @@
 package controllers
 
 import (
+	"fmt"
+
 	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/JonatanOrdonez/tr-backend/models"
 	"github.com/valyala/fasthttp"
 )
 
 // BaseHandler: Structure used to store a domainRepo and domainService object
 type BaseHandler struct {
 	domainService interfaces.IDomainService
+	logger        interfaces.ILogger
 }
 
 // NewDomainHandler: Receives a reference to the domainRepo and domainService interfaces and stores them in the BaseHandler structure
 // Params:
 // (domainRepo): Reference to a domainRepo interface
 // (domainService): Reference to a domainService interface
+// (logger): Reference to the logger
 // Return:
 // (*BaseHandler): Reference to the BaseHandler object
-func NewDomainController(domainService interfaces.IDomainService) *BaseHandler {
-	return &BaseHandler{domainService: domainService}
+func NewDomainController(domainService interfaces.IDomainService, logger interfaces.ILogger) *BaseHandler {
+	return &BaseHandler{domainService: domainService, logger: logger}
 }
 
 // ResponseCheckDomain: Handles the request that gets at the endpoint /api/v1/analyze.
 // If the request has the host query param empty (""), the redirection is made to ResponseDomains
-// Else, call the function CheckDomain from the DomainService interface
+// Else, call the function CheckDomain from the DomainService interface, which requires the scans:trigger scope
 // Params:
 // (ctx): Request reference
 func (h *BaseHandler) ResponseCheckDomain(ctx *fasthttp.RequestCtx) {
 	hostPath := string(ctx.QueryArgs().Peek("host"))
 	if hostPath == "" {
 		h.ResponseDomains(ctx)
+	} else if hasScope(ctx, models.ScopeScansTrigger) == false {
+		raiseError(ctx, 403, fmt.Sprintf("Scope %s is required", models.ScopeScansTrigger))
 	} else {
-		jsonBody, domainErr := h.domainService.CheckDomain(hostPath)
+		requestCtx := requestContext(ctx)
+		jsonBody, domainErr := h.domainService.WithOrg(orgID(ctx)).WithContext(requestCtx).CheckDomain(hostPath)
 		if domainErr != nil {
+			h.logger.Warn(requestCtx, "Scan failed", "host", hostPath, "error", domainErr)
 			h.domainService.RaiseError(ctx, 400, domainErr.Error())
 		} else {
 			ctx.SetContentType("application/json; charset=utf-8")
@@ -41,11 +50,16 @@ func (h *BaseHandler) ResponseCheckDomain(ctx *fasthttp.RequestCtx) {
 	}
 }
 
-// ResponseDomains: Returns a JSON http response with the domain Slice
+// ResponseDomains: Returns a JSON http response with the domain Slice.
+// The tag query param, that can be repeated, keeps only the domains that have every given tag
 // Params:
 // (ctx): Request reference
 func (h *BaseHandler) ResponseDomains(ctx *fasthttp.RequestCtx) {
-	jsonDomains, err := h.domainService.GetDomains()
+	tags := make([]string, 0)
+	for _, tag := range ctx.QueryArgs().PeekMulti("tag") {
+		tags = append(tags, string(tag))
+	}
+	jsonDomains, err := h.domainService.WithOrg(orgID(ctx)).GetDomains(tags)
 	if err != nil {
 		h.domainService.RaiseError(ctx, 400, err.Error())
 	} else {
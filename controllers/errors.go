@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+	"github.com/valyala/fasthttp"
+)
+
+// errorMessageKey: Key of the user value that stores the message of the error response, written in the request log line
+const errorMessageKey = "errorMessage"
+
+// raiseError: Takes a ctx reference and responses a JSON error to the client
+// Params:
+// (ctx): Request reference
+// (errorCode): Error code
+// (errorMessage): Error message, also written in the request log line
+func raiseError(ctx *fasthttp.RequestCtx, errorCode int, errorMessage string) {
+	errorEntity := &models.Error{Code: errorCode, Message: errorMessage}
+	jsonBody, _ := json.Marshal(errorEntity)
+	ctx.SetContentType("application/json; charset=utf-8")
+	ctx.SetStatusCode(errorCode)
+	ctx.Response.SetBody(jsonBody)
+	ctx.SetUserValue(errorMessageKey, errorMessage)
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"bufio"
+	"fmt"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	models "github.com/JonatanOrdonez/tr-backend/models"
+	"github.com/valyala/fasthttp"
+)
+
+// ExportHandler: Structure used to store an exportService object
+type ExportHandler struct {
+	exportService interfaces.IExportService
+	logger        interfaces.ILogger
+}
+
+// NewExportController: Receives a reference to the exportService interface and stores it in the ExportHandler structure
+// Params:
+// (exportService): Reference to an exportService interface
+// (logger): Reference to the logger
+// Return:
+// (*ExportHandler): Reference to the ExportHandler object
+func NewExportController(exportService interfaces.IExportService, logger interfaces.ILogger) *ExportHandler {
+	return &ExportHandler{exportService: exportService, logger: logger}
+}
+
+// ResponseExport: Handles the request that gets at the endpoint /api/v1/domains/export.
+// Streams the domains as csv (default) or ndjson, one row per domain or, with level=server, per server.
+// The router cannot register a static segment next to the :host parameter, so this handler is mounted on
+// /api/v1/domains/:host and any host other than "export" is not found
+// Params:
+// (ctx): Request reference
+func (h *ExportHandler) ResponseExport(ctx *fasthttp.RequestCtx) {
+	if hostPath, _ := ctx.UserValue("host").(string); hostPath != "export" {
+		raiseError(ctx, 404, "Not found")
+		return
+	}
+	format := string(ctx.QueryArgs().Peek("format"))
+	if format == "" {
+		format = models.ExportCSV
+	}
+	level := string(ctx.QueryArgs().Peek("level"))
+	if level == "" {
+		level = models.ExportDomainLevel
+	}
+	exportService := h.exportService.WithOrg(orgID(ctx))
+	if err := exportService.ValidateExport(format, level); err != nil {
+		raiseError(ctx, 400, err.Error())
+		return
+	}
+	if format == models.ExportCSV {
+		ctx.SetContentType("text/csv; charset=utf-8")
+	} else {
+		ctx.SetContentType("application/x-ndjson; charset=utf-8")
+	}
+	ctx.Response.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"domains.%s\"", format))
+	ctx.SetStatusCode(200)
+	requestCtx := requestContext(ctx)
+	ctx.SetBodyStreamWriter(func(writer *bufio.Writer) {
+		if err := exportService.WriteDomains(writer, format, level); err != nil {
+			h.logger.Error(requestCtx, "Export failed", "format", format, "level", level, "error", err)
+		}
+		writer.Flush()
+	})
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// OrgHandler: Structure used to store an orgService object
+type OrgHandler struct {
+	orgService interfaces.IOrgService
+}
+
+// NewOrgController: Receives a reference to the orgService interface and stores it in the OrgHandler structure
+// Params:
+// (orgService): Reference to an orgService interface
+// Return:
+// (*OrgHandler): Reference to the OrgHandler object
+func NewOrgController(orgService interfaces.IOrgService) *OrgHandler {
+	return &OrgHandler{orgService: orgService}
+}
+
+// ResponseOrg: Handles the request that gets at the endpoint /api/v1/org.
+// Returns the organization of the authenticated principal
+// Params:
+// (ctx): Request reference
+func (h *OrgHandler) ResponseOrg(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.orgService.GetOrg(orgID(ctx))
+	if err != nil {
+		raiseError(ctx, 404, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
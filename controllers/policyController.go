@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"strconv"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// PolicyHandler: Structure used to store a policyService object
+type PolicyHandler struct {
+	policyService interfaces.IPolicyService
+}
+
+// NewPolicyController: Receives a reference to the policyService interface and stores it in the PolicyHandler structure
+// Params:
+// (policyService): Reference to a policyService interface
+// Return:
+// (*PolicyHandler): Reference to the PolicyHandler object
+func NewPolicyController(policyService interfaces.IPolicyService) *PolicyHandler {
+	return &PolicyHandler{policyService: policyService}
+}
+
+// ResponseCreatePolicy: Handles the POST request that gets at the endpoint /api/v1/policies.
+// Receives a JSON body with the name, type and parameters of the policy
+// Params:
+// (ctx): Request reference
+func (h *PolicyHandler) ResponseCreatePolicy(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.policyService.WithOrg(orgID(ctx)).CreatePolicy(ctx.PostBody())
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(201)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponsePolicies: Handles the GET request that gets at the endpoint /api/v1/policies
+// Params:
+// (ctx): Request reference
+func (h *PolicyHandler) ResponsePolicies(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.policyService.WithOrg(orgID(ctx)).GetPolicies()
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseDeletePolicy: Handles the DELETE request that gets at the endpoint /api/v1/policies/:id
+// Params:
+// (ctx): Request reference
+func (h *PolicyHandler) ResponseDeletePolicy(ctx *fasthttp.RequestCtx) {
+	id, parseErr := strconv.ParseInt(ctx.UserValue("id").(string), 10, 64)
+	if parseErr != nil {
+		raiseError(ctx, 400, "Invalid policy id")
+		return
+	}
+	if err := h.policyService.WithOrg(orgID(ctx)).DeletePolicy(id); err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetStatusCode(204)
+	}
+}
+
+// ResponseCompliance: Handles the GET request that gets at the endpoint /api/v1/compliance.
+// Returns the domains that violate at least one policy
+// Params:
+// (ctx): Request reference
+func (h *PolicyHandler) ResponseCompliance(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.policyService.WithOrg(orgID(ctx)).GetComplianceSummary()
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/JonatanOrdonez/tr-backend/models"
+	"github.com/valyala/fasthttp"
+)
+
+// principalKey: Key of the user value that stores the authenticated principal of a request
+const principalKey = "principal"
+
+// apiKeyHeader: Header that carries the API key of a request
+const apiKeyHeader = "X-API-Key"
+
+// anonymousSubject: Subject of the principal of the requests without credentials
+const anonymousSubject = "anonymous"
+
+// SetPrincipal: Stores the authenticated principal in the request
+// Params:
+// (ctx): Request reference
+// (principal): Reference to the principal
+func SetPrincipal(ctx *fasthttp.RequestCtx, principal *models.Principal) {
+	ctx.SetUserValue(principalKey, principal)
+}
+
+// GetPrincipal: Returns the authenticated principal of the request
+// Params:
+// (ctx): Request reference
+// Return:
+// (*models.Principal): Reference to the principal, nil if the request is not authenticated
+func GetPrincipal(ctx *fasthttp.RequestCtx) *models.Principal {
+	principal, _ := ctx.UserValue(principalKey).(*models.Principal)
+	return principal
+}
+
+// Authenticate: Middleware that identifies the principal of every request from its X-API-Key header or,
+// when bearer tokens are enabled, from the JWT of its Authorization header.
+// A request with an invalid key or token, or whose token names an organization that does not exist, is rejected.
+// A request without credentials is rejected too, unless the anonymous read mode is enabled, in which case it
+// can read the domains of the default organization
+// Params:
+// (next): Handler of the request
+// (apiKeyService): Reference to the apiKeyService interface that checks the keys
+// (tokenVerifier): Reference to the tokenVerifier interface that checks the bearer tokens, nil if they are disabled
+// (orgService): Reference to the orgService interface that checks the organization of the tokens
+// (anonymousRead): True to let the requests without credentials read
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func Authenticate(next fasthttp.RequestHandler, apiKeyService interfaces.IApiKeyService, tokenVerifier interfaces.ITokenVerifier, orgService interfaces.IOrgService, anonymousRead bool) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		key := string(ctx.Request.Header.Peek(apiKeyHeader))
+		authorization := string(ctx.Request.Header.Peek("Authorization"))
+		var principal *models.Principal
+		var err error
+		switch {
+		case key != "":
+			principal, err = apiKeyService.Authenticate(key)
+		case strings.HasPrefix(strings.ToLower(authorization), "bearer "):
+			if tokenVerifier == nil {
+				raiseError(ctx, 401, "Bearer tokens are not enabled")
+				return
+			}
+			principal, err = tokenVerifier.Verify(strings.TrimSpace(authorization[len("bearer "):]))
+			if err == nil {
+				err = orgService.CheckOrg(principal.OrgId)
+			}
+		case anonymousRead:
+			principal = &models.Principal{Subject: anonymousSubject, OrgId: models.DefaultOrgId, Scopes: []string{models.ScopeDomainsRead}}
+		default:
+			raiseError(ctx, 401, "Authentication is required")
+			return
+		}
+		if err != nil {
+			raiseError(ctx, 401, err.Error())
+			return
+		}
+		SetPrincipal(ctx, principal)
+		next(ctx)
+	}
+}
+
+// RequireScope: Middleware that rejects the requests whose principal was not granted a scope
+// Params:
+// (scope): Scope required by the handler
+// (next): Handler of the request
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func RequireScope(scope string, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if hasScope(ctx, scope) == false {
+			raiseError(ctx, 403, fmt.Sprintf("Scope %s is required", scope))
+			return
+		}
+		next(ctx)
+	}
+}
+
+// hasScope: Auxiliary function that checks if the principal of the request was granted a scope
+// Params:
+// (ctx): Request reference
+// (scope): Scope to be checked
+// Return:
+// (bool): True if the scope was granted. False if not
+func hasScope(ctx *fasthttp.RequestCtx, scope string) bool {
+	principal := GetPrincipal(ctx)
+	return principal != nil && principal.HasScope(scope)
+}
+
+// orgID: Auxiliary function that returns the organization of the authenticated principal
+// Params:
+// (ctx): Request reference
+// Return:
+// (int64): Id of the organization, -1 if the request is not authenticated, which matches no record
+func orgID(ctx *fasthttp.RequestCtx) int64 {
+	principal := GetPrincipal(ctx)
+	if principal == nil {
+		return -1
+	}
+	return principal.OrgId
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// RateLimitHandler: Structure used to store the limiters of the reads and of the scans, and the proxies whose X-Forwarded-For header is trusted
+type RateLimitHandler struct {
+	readLimiter    interfaces.IRateLimiter
+	scanLimiter    interfaces.IRateLimiter
+	trustedProxies []*net.IPNet
+}
+
+// NewRateLimitMiddleware: Receives the limiters and the trusted proxies and stores them in the RateLimitHandler structure
+// Params:
+// (readLimiter): Reference to the limiter of the requests that read stored data
+// (scanLimiter): Reference to the limiter of the requests that launch SSL Labs assessments
+// (trustedProxies): Networks of the proxies in front of the server
+// Return:
+// (*RateLimitHandler): Reference to the RateLimitHandler object
+func NewRateLimitMiddleware(readLimiter interfaces.IRateLimiter, scanLimiter interfaces.IRateLimiter, trustedProxies []*net.IPNet) *RateLimitHandler {
+	return &RateLimitHandler{readLimiter: readLimiter, scanLimiter: scanLimiter, trustedProxies: trustedProxies}
+}
+
+// Reads: Middleware that charges the request to the read budget of the client
+// Params:
+// (next): Handler of the request
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func (h *RateLimitHandler) Reads(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		h.limit(ctx, h.readLimiter, next)
+	}
+}
+
+// Scans: Middleware that charges the request to the scan budget of the client
+// Params:
+// (next): Handler of the request
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func (h *RateLimitHandler) Scans(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		h.limit(ctx, h.scanLimiter, next)
+	}
+}
+
+// Analyze: Middleware of /api/v1/analyze, which lists the stored domains without the host query param
+// and launches an assessment with it. The request is charged to the read or the scan budget accordingly
+// Params:
+// (next): Handler of the request
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func (h *RateLimitHandler) Analyze(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if len(ctx.QueryArgs().Peek("host")) == 0 {
+			h.limit(ctx, h.readLimiter, next)
+		} else {
+			h.limit(ctx, h.scanLimiter, next)
+		}
+	}
+}
+
+// limit: Takes a token from the bucket of the client, sets the X-RateLimit headers and calls the handler,
+// or responds 429 with the Retry-After header if the bucket is empty
+// Params:
+// (ctx): Request reference
+// (limiter): Reference to the limiter of the budget
+// (next): Handler of the request
+func (h *RateLimitHandler) limit(ctx *fasthttp.RequestCtx, limiter interfaces.IRateLimiter, next fasthttp.RequestHandler) {
+	result := limiter.Take(h.clientKey(ctx))
+	ctx.Response.Header.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	ctx.Response.Header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	ctx.Response.Header.Set("X-RateLimit-Reset", strconv.FormatInt(result.Reset, 10))
+	if result.Allowed == false {
+		ctx.Response.Header.Set("Retry-After", strconv.FormatInt(result.RetryAfter, 10))
+		raiseError(ctx, 429, "Rate limit exceeded")
+		return
+	}
+	next(ctx)
+}
+
+// clientKey: Identifies the client of a request: the subject of its API key or token, or its ip address if it is anonymous
+// Params:
+// (ctx): Request reference
+// Return:
+// (string): Key of the client
+func (h *RateLimitHandler) clientKey(ctx *fasthttp.RequestCtx) string {
+	if principal := GetPrincipal(ctx); principal != nil && principal.Subject != anonymousSubject {
+		return principal.Subject
+	}
+	return "ip:" + h.clientIP(ctx)
+}
+
+// clientIP: Returns the ip address of the client. When the request comes from a trusted proxy, the
+// X-Forwarded-For header is read from right to left and the first address that is not a trusted proxy is taken
+// Params:
+// (ctx): Request reference
+// Return:
+// (string): Ip address of the client
+func (h *RateLimitHandler) clientIP(ctx *fasthttp.RequestCtx) string {
+	ip := ctx.RemoteIP()
+	if h.isTrusted(ip) == false {
+		return ip.String()
+	}
+	forwarded := strings.Split(string(ctx.Request.Header.Peek("X-Forwarded-For")), ",")
+	for ii := len(forwarded) - 1; ii >= 0; ii-- {
+		forwardedIP := net.ParseIP(strings.TrimSpace(forwarded[ii]))
+		if forwardedIP == nil {
+			break
+		}
+		ip = forwardedIP
+		if h.isTrusted(ip) == false {
+			break
+		}
+	}
+	return ip.String()
+}
+
+// isTrusted: Checks if an ip address belongs to a trusted proxy
+// Params:
+// (ip): Ip address
+// Return:
+// (bool): True if the address is trusted. False if not
+func (h *RateLimitHandler) isTrusted(ip net.IP) bool {
+	for _, network := range h.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
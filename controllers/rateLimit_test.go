@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+	"github.com/valyala/fasthttp"
+)
+
+type fakeRateLimiter struct {
+	result models.RateLimit
+	keys   []string
+}
+
+func (l *fakeRateLimiter) Take(key string) models.RateLimit {
+	l.keys = append(l.keys, key)
+	return l.result
+}
+
+func newTestCtx(t *testing.T, remoteIP string, forwardedFor string) *fasthttp.RequestCtx {
+	request := &fasthttp.Request{}
+	request.SetRequestURI("/api/v1/domains/example.com/uptime")
+	if forwardedFor != "" {
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(request, &net.TCPAddr{IP: net.ParseIP(remoteIP), Port: 40000}, nil)
+	return ctx
+}
+
+func newTestRateLimitHandler(t *testing.T, limiter *fakeRateLimiter) *RateLimitHandler {
+	networks := make([]*net.IPNet, 0)
+	for _, cidr := range []string{"10.0.0.0/8", "192.168.1.1/32"} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatalf("net.ParseCIDR(%s): %v", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return NewRateLimitMiddleware(limiter, limiter, networks)
+}
+
+func TestClientIP(t *testing.T) {
+	handler := newTestRateLimitHandler(t, &fakeRateLimiter{})
+	cases := []struct {
+		name         string
+		remoteIP     string
+		forwardedFor string
+		want         string
+	}{
+		{"untrusted peer", "203.0.113.9", "", "203.0.113.9"},
+		{"spoofed header from an untrusted peer", "203.0.113.9", "198.51.100.7", "203.0.113.9"},
+		{"trusted peer without header", "10.0.0.1", "", "10.0.0.1"},
+		{"trusted peer", "10.0.0.1", "198.51.100.7", "198.51.100.7"},
+		{"chain of trusted proxies", "10.0.0.1", "198.51.100.7, 192.168.1.1, 10.0.0.2", "198.51.100.7"},
+		{"spoofed entries before the real client", "10.0.0.1", "1.2.3.4, 5.6.7.8, 198.51.100.7, 10.0.0.2", "198.51.100.7"},
+		{"every entry trusted", "10.0.0.1", "10.0.0.3, 10.0.0.2", "10.0.0.3"},
+		{"malformed last entry", "10.0.0.1", "198.51.100.7, not-an-ip", "10.0.0.1"},
+		{"malformed entry after a trusted proxy", "10.0.0.1", "198.51.100.7, garbage, 10.0.0.2", "10.0.0.2"},
+		{"malformed entry before the client", "10.0.0.1", "garbage, 198.51.100.7", "198.51.100.7"},
+		{"ipv6 client", "10.0.0.1", " 2001:db8::1 ", "2001:db8::1"},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			if got := handler.clientIP(newTestCtx(t, test.remoteIP, test.forwardedFor)); got != test.want {
+				t.Errorf("clientIP = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	handler := newTestRateLimitHandler(t, &fakeRateLimiter{})
+	ctx := newTestCtx(t, "203.0.113.9", "")
+	if got := handler.clientKey(ctx); got != "ip:203.0.113.9" {
+		t.Errorf("clientKey without principal = %s, want ip:203.0.113.9", got)
+	}
+	SetPrincipal(ctx, &models.Principal{Subject: anonymousSubject})
+	if got := handler.clientKey(ctx); got != "ip:203.0.113.9" {
+		t.Errorf("clientKey of an anonymous principal = %s, want ip:203.0.113.9", got)
+	}
+	SetPrincipal(ctx, &models.Principal{Subject: "apikey:7"})
+	if got := handler.clientKey(ctx); got != "apikey:7" {
+		t.Errorf("clientKey of an API key = %s, want apikey:7", got)
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	cases := []struct {
+		name       string
+		result     models.RateLimit
+		wantStatus int
+		wantCalled bool
+		wantHeader map[string]string
+	}{
+		{"allowed", models.RateLimit{Allowed: true, Limit: 60, Remaining: 59, Reset: 1}, 200, true,
+			map[string]string{"X-RateLimit-Limit": "60", "X-RateLimit-Remaining": "59", "X-RateLimit-Reset": "1", "Retry-After": ""}},
+		{"denied", models.RateLimit{Allowed: false, Limit: 60, Remaining: 0, RetryAfter: 2, Reset: 60}, 429, false,
+			map[string]string{"X-RateLimit-Limit": "60", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "60", "Retry-After": "2"}},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			limiter := &fakeRateLimiter{result: test.result}
+			called := false
+			next := func(ctx *fasthttp.RequestCtx) { called = true }
+			ctx := newTestCtx(t, "203.0.113.9", "")
+			newTestRateLimitHandler(t, limiter).Reads(next)(ctx)
+			if called != test.wantCalled || ctx.Response.StatusCode() != test.wantStatus {
+				t.Errorf("called = %v, status = %d, want %v and %d", called, ctx.Response.StatusCode(), test.wantCalled, test.wantStatus)
+			}
+			for name, want := range test.wantHeader {
+				if got := string(ctx.Response.Header.Peek(name)); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			if len(limiter.keys) != 1 || limiter.keys[0] != "ip:203.0.113.9" {
+				t.Errorf("limiter keys = %v, want [ip:203.0.113.9]", limiter.keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"strconv"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// RegistrationHandler: Structure used to store a registrationService object
+type RegistrationHandler struct {
+	registrationService interfaces.IRegistrationService
+}
+
+// NewRegistrationController: Receives a reference to the registrationService interface and stores it in the RegistrationHandler structure
+// Params:
+// (registrationService): Reference to a registrationService interface
+// Return:
+// (*RegistrationHandler): Reference to the RegistrationHandler object
+func NewRegistrationController(registrationService interfaces.IRegistrationService) *RegistrationHandler {
+	return &RegistrationHandler{registrationService: registrationService}
+}
+
+// ResponseExpiring: Handles the request that gets at the endpoint /api/v1/registrations/expiring.
+// Returns the domain registrations that expire within the days query param (30 by default)
+// Params:
+// (ctx): Request reference
+func (h *RegistrationHandler) ResponseExpiring(ctx *fasthttp.RequestCtx) {
+	days := 30
+	if daysParam := string(ctx.QueryArgs().Peek("days")); daysParam != "" {
+		parsedDays, parseErr := strconv.Atoi(daysParam)
+		if parseErr != nil {
+			raiseError(ctx, 400, "Invalid days")
+			return
+		}
+		days = parsedDays
+	}
+	jsonBody, err := h.registrationService.WithOrg(orgID(ctx)).GetExpiring(days)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
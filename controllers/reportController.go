@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// ReportHandler: Structure used to store a reportService object
+type ReportHandler struct {
+	reportService interfaces.IReportService
+}
+
+// NewReportController: Receives a reference to the reportService interface and stores it in the ReportHandler structure
+// Params:
+// (reportService): Reference to a reportService interface
+// Return:
+// (*ReportHandler): Reference to the ReportHandler object
+func NewReportController(reportService interfaces.IReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// ResponseReport: Handles the request that gets at the endpoint /api/v1/domains/:host/report.
+// Returns the security report of the domain in the format query param (html by default)
+// Params:
+// (ctx): Request reference
+func (h *ReportHandler) ResponseReport(ctx *fasthttp.RequestCtx) {
+	hostPath, _ := ctx.UserValue("host").(string)
+	format := string(ctx.QueryArgs().Peek("format"))
+	if format == "" {
+		format = "html"
+	}
+	body, err := h.reportService.WithOrg(orgID(ctx)).GetReport(hostPath, format)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("text/html; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(body)
+	}
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/JonatanOrdonez/tr-backend/logging"
+	"github.com/JonatanOrdonez/tr-backend/metrics"
+	"github.com/JonatanOrdonez/tr-backend/tracing"
+	"github.com/valyala/fasthttp"
+)
+
+// requestIDKey: Key of the user value that stores the id of a request
+const requestIDKey = "requestId"
+
+// requestIDHeader: Header that carries the id of a request
+const requestIDHeader = "X-Request-Id"
+
+// requestID: Auxiliary function that returns the id of the request: the X-Request-Id header set by the
+// client or a proxy, or a random id. The id is echoed in the X-Request-Id header of the response
+// Params:
+// (ctx): Request reference
+// Return:
+// (string): Id of the request
+func requestID(ctx *fasthttp.RequestCtx) string {
+	if id, ok := ctx.UserValue(requestIDKey).(string); ok {
+		return id
+	}
+	id := string(ctx.Request.Header.Peek(requestIDHeader))
+	if id == "" || len(id) > 128 {
+		random := make([]byte, 16)
+		rand.Read(random)
+		id = hex.EncodeToString(random)
+	}
+	ctx.SetUserValue(requestIDKey, id)
+	ctx.Response.Header.Set(requestIDHeader, id)
+	return id
+}
+
+// RequestID: Middleware that assigns its id to every request, generated or propagated from the X-Request-Id header,
+// and writes a log line when the request ends. The id is added to the lines logged with requestContext
+// Params:
+// (next): Handler of the request
+// (logger): Logger of the request lines
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func RequestID(next fasthttp.RequestHandler, logger interfaces.ILogger) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+		requestID(ctx)
+		next(ctx)
+		status := ctx.Response.StatusCode()
+		keyValues := []interface{}{"method", string(ctx.Method()), "route", metrics.RouteOf(ctx), "path", string(ctx.Path()), "status", status, "duration_ms", time.Since(start).Milliseconds()}
+		if message, ok := ctx.UserValue(errorMessageKey).(string); ok {
+			keyValues = append(keyValues, "error", message)
+		}
+		switch {
+		case status >= 500:
+			logger.Error(requestContext(ctx), "Request failed", keyValues...)
+		case status >= 400:
+			logger.Warn(requestContext(ctx), "Request rejected", keyValues...)
+		default:
+			logger.Info(requestContext(ctx), "Request handled", keyValues...)
+		}
+	}
+}
+
+// requestContext: Auxiliary function that returns the context passed to the services: the context of the request
+// span, carrying the id of the request for the log lines
+// Params:
+// (ctx): Request reference
+// Return:
+// (context.Context): Context of the request
+func requestContext(ctx *fasthttp.RequestCtx) context.Context {
+	return logging.WithRequestID(tracing.Context(ctx), requestID(ctx))
+}
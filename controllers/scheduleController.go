@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// ScheduleHandler: Structure used to store a schedulerService object
+type ScheduleHandler struct {
+	schedulerService interfaces.ISchedulerService
+}
+
+// NewScheduleController: Receives a reference to the schedulerService interface and stores it in the ScheduleHandler structure
+// Params:
+// (schedulerService): Reference to a schedulerService interface
+// Return:
+// (*ScheduleHandler): Reference to the ScheduleHandler object
+func NewScheduleController(schedulerService interfaces.ISchedulerService) *ScheduleHandler {
+	return &ScheduleHandler{schedulerService: schedulerService}
+}
+
+// ResponseSchedule: Handles the GET request that gets at the endpoint /api/v1/domains/:host/schedule.
+// Returns the rescan interval and the next run of the domain
+// Params:
+// (ctx): Request reference
+func (h *ScheduleHandler) ResponseSchedule(ctx *fasthttp.RequestCtx) {
+	hostPath, _ := ctx.UserValue("host").(string)
+	jsonBody, err := h.schedulerService.WithOrg(orgID(ctx)).GetSchedule(hostPath)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseSetSchedule: Handles the PUT request that gets at the endpoint /api/v1/domains/:host/schedule.
+// Receives a JSON body such as {"interval": "12h"} and changes the rescan interval of the domain
+// Params:
+// (ctx): Request reference
+func (h *ScheduleHandler) ResponseSetSchedule(ctx *fasthttp.RequestCtx) {
+	hostPath, _ := ctx.UserValue("host").(string)
+	var body struct {
+		Interval string `json:"interval"`
+	}
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil {
+		raiseError(ctx, 400, "Invalid body")
+		return
+	}
+	jsonBody, err := h.schedulerService.WithOrg(orgID(ctx)).SetInterval(hostPath, body.Interval)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseSetTagSchedule: Handles the PUT request that gets at the endpoint /api/v1/tags/:tag/schedule.
+// Receives a JSON body such as {"interval": "12h"} and changes the rescan interval of every domain with the tag
+// Params:
+// (ctx): Request reference
+func (h *ScheduleHandler) ResponseSetTagSchedule(ctx *fasthttp.RequestCtx) {
+	tag, _ := ctx.UserValue("tag").(string)
+	var body struct {
+		Interval string `json:"interval"`
+	}
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil {
+		raiseError(ctx, 400, "Invalid body")
+		return
+	}
+	jsonBody, err := h.schedulerService.WithOrg(orgID(ctx)).SetTagInterval(tag, body.Interval)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
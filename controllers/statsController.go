@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"strconv"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// StatsHandler: Structure used to store a statsService object
+type StatsHandler struct {
+	statsService interfaces.IStatsService
+}
+
+// NewStatsController: Receives a reference to the statsService interface and stores it in the StatsHandler structure
+// Params:
+// (statsService): Reference to a statsService interface
+// Return:
+// (*StatsHandler): Reference to the StatsHandler object
+func NewStatsController(statsService interfaces.IStatsService) *StatsHandler {
+	return &StatsHandler{statsService: statsService}
+}
+
+// ResponseStats: Handles the request that gets at the endpoint /api/v1/stats.
+// Returns the aggregate view of the domains. The days query param (7 by default) is the window of servers_changed
+// Params:
+// (ctx): Request reference
+func (h *StatsHandler) ResponseStats(ctx *fasthttp.RequestCtx) {
+	days := 7
+	if daysParam := string(ctx.QueryArgs().Peek("days")); daysParam != "" {
+		parsedDays, parseErr := strconv.Atoi(daysParam)
+		if parseErr != nil {
+			raiseError(ctx, 400, "Invalid days")
+			return
+		}
+		days = parsedDays
+	}
+	jsonBody, err := h.statsService.WithOrg(orgID(ctx)).GetStats(days)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// TagHandler: Structure used to store a tagService object
+type TagHandler struct {
+	tagService interfaces.ITagService
+}
+
+// NewTagController: Receives a reference to the tagService interface and stores it in the TagHandler structure
+// Params:
+// (tagService): Reference to a tagService interface
+// Return:
+// (*TagHandler): Reference to the TagHandler object
+func NewTagController(tagService interfaces.ITagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+// ResponseTags: Handles the request that gets at the endpoint /api/v1/tags.
+// Returns the tags in use and the number of domains of each one
+// Params:
+// (ctx): Request reference
+func (h *TagHandler) ResponseTags(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.tagService.WithOrg(orgID(ctx)).GetTags()
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseDomainTags: Handles the GET request that gets at the endpoint /api/v1/domains/:host/tags.
+// Returns the tags of the domain
+// Params:
+// (ctx): Request reference
+func (h *TagHandler) ResponseDomainTags(ctx *fasthttp.RequestCtx) {
+	hostPath, _ := ctx.UserValue("host").(string)
+	jsonBody, err := h.tagService.WithOrg(orgID(ctx)).GetDomainTags(hostPath)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseAddDomainTags: Handles the POST request that gets at the endpoint /api/v1/domains/:host/tags.
+// Receives a JSON body such as {"tags": ["prod", "customer:acme"]} and attaches the tags to the domain
+// Params:
+// (ctx): Request reference
+func (h *TagHandler) ResponseAddDomainTags(ctx *fasthttp.RequestCtx) {
+	hostPath, _ := ctx.UserValue("host").(string)
+	jsonBody, err := h.tagService.WithOrg(orgID(ctx)).AddDomainTags(hostPath, ctx.PostBody())
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseRemoveDomainTag: Handles the DELETE request that gets at the endpoint /api/v1/domains/:host/tags/:tag
+// Params:
+// (ctx): Request reference
+func (h *TagHandler) ResponseRemoveDomainTag(ctx *fasthttp.RequestCtx) {
+	hostPath, _ := ctx.UserValue("host").(string)
+	tag, _ := ctx.UserValue("tag").(string)
+	if err := h.tagService.WithOrg(orgID(ctx)).RemoveDomainTag(hostPath, tag); err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetStatusCode(204)
+	}
+}
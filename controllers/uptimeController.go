@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// UptimeHandler: Structure used to store an uptimeService object
+type UptimeHandler struct {
+	uptimeService interfaces.IUptimeService
+}
+
+// NewUptimeController: Receives a reference to the uptimeService interface and stores it in the UptimeHandler structure
+// Params:
+// (uptimeService): Reference to an uptimeService interface
+// Return:
+// (*UptimeHandler): Reference to the UptimeHandler object
+func NewUptimeController(uptimeService interfaces.IUptimeService) *UptimeHandler {
+	return &UptimeHandler{uptimeService: uptimeService}
+}
+
+// ResponseUptime: Handles the request that gets at the endpoint /api/v1/domains/:host/uptime.
+// Returns the availability of the domain in the window query param (30d by default)
+// Params:
+// (ctx): Request reference
+func (h *UptimeHandler) ResponseUptime(ctx *fasthttp.RequestCtx) {
+	hostPath, _ := ctx.UserValue("host").(string)
+	window := string(ctx.QueryArgs().Peek("window"))
+	jsonBody, err := h.uptimeService.WithOrg(orgID(ctx)).GetUptime(hostPath, window)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
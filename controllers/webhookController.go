@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"strconv"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/valyala/fasthttp"
+)
+
+// WebhookHandler: Structure used to store a webhookService object
+type WebhookHandler struct {
+	webhookService interfaces.IWebhookService
+}
+
+// NewWebhookController: Receives a reference to the webhookService interface and stores it in the WebhookHandler structure
+// Params:
+// (webhookService): Reference to a webhookService interface
+// Return:
+// (*WebhookHandler): Reference to the WebhookHandler object
+func NewWebhookController(webhookService interfaces.IWebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// ResponseCreateWebhook: Handles the POST request that gets at the endpoint /api/v1/webhooks.
+// Receives a JSON body with the url, secret and events of the subscription
+// Params:
+// (ctx): Request reference
+func (h *WebhookHandler) ResponseCreateWebhook(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.webhookService.WithOrg(orgID(ctx)).CreateWebhook(ctx.PostBody())
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(201)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseWebhooks: Handles the GET request that gets at the endpoint /api/v1/webhooks
+// Params:
+// (ctx): Request reference
+func (h *WebhookHandler) ResponseWebhooks(ctx *fasthttp.RequestCtx) {
+	jsonBody, err := h.webhookService.WithOrg(orgID(ctx)).GetWebhooks()
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
+
+// ResponseDeleteWebhook: Handles the DELETE request that gets at the endpoint /api/v1/webhooks/:id
+// Params:
+// (ctx): Request reference
+func (h *WebhookHandler) ResponseDeleteWebhook(ctx *fasthttp.RequestCtx) {
+	id, parseErr := strconv.ParseInt(ctx.UserValue("id").(string), 10, 64)
+	if parseErr != nil {
+		raiseError(ctx, 400, "Invalid webhook id")
+		return
+	}
+	if err := h.webhookService.WithOrg(orgID(ctx)).DeleteWebhook(id); err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetStatusCode(204)
+	}
+}
+
+// ResponseDeliveries: Handles the GET request that gets at the endpoint /api/v1/webhooks/:id/deliveries.
+// Returns the delivery log of the webhook
+// Params:
+// (ctx): Request reference
+func (h *WebhookHandler) ResponseDeliveries(ctx *fasthttp.RequestCtx) {
+	id, parseErr := strconv.ParseInt(ctx.UserValue("id").(string), 10, 64)
+	if parseErr != nil {
+		raiseError(ctx, 400, "Invalid webhook id")
+		return
+	}
+	jsonBody, err := h.webhookService.WithOrg(orgID(ctx)).GetDeliveries(id)
+	if err != nil {
+		raiseError(ctx, 400, err.Error())
+	} else {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetStatusCode(200)
+		ctx.Response.SetBody(jsonBody)
+	}
+}
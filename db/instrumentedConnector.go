@@ -0,0 +1,197 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/metrics"
+	"github.com/JonatanOrdonez/tr-backend/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTable: Finds the main table of a statement, after FROM, INTO, UPDATE or TABLE
+var queryTable = regexp.MustCompile(`(?i)\b(?:from|into|update|table)\s+([a-z_][a-z0-9_]*)`)
+
+// instrumentedConnector: Connector that measures every query of the connections it opens, and traces the ones made with a traced context
+type instrumentedConnector struct {
+	base driver.Connector
+}
+
+// Connect: Opens a connection with the wrapped connector and measures its queries
+// Params:
+// (ctx): Context of the connection
+// Return:
+// (driver.Conn): Measured connection
+// (error): Error if the connection fails
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{conn: conn}, nil
+}
+
+// Driver: Returns the driver of the wrapped connector
+// Return:
+// (driver.Driver): Driver
+func (c *instrumentedConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// instrumentedConn: Connection that records the latency of its queries, by operation and table
+type instrumentedConn struct {
+	conn driver.Conn
+}
+
+// Prepare: Prepares a statement whose executions are measured
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext: Prepares a statement whose executions are measured
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{stmt: stmt, query: query}, nil
+}
+
+// Close: Closes the wrapped connection
+func (c *instrumentedConn) Close() error {
+	return c.conn.Close()
+}
+
+// Begin: Starts a transaction
+func (c *instrumentedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx: Starts a transaction, the queries made inside it go through the same connection
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.conn.Begin()
+}
+
+// QueryContext: Runs a query without preparing it, driver.ErrSkip makes database/sql fall back to a statement
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if ok == false {
+		return nil, driver.ErrSkip
+	}
+	ctx, finish := startQuery(ctx, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	finish(err)
+	return rows, err
+}
+
+// ExecContext: Runs a statement without preparing it, driver.ErrSkip makes database/sql fall back to a statement
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if ok == false {
+		return nil, driver.ErrSkip
+	}
+	ctx, finish := startQuery(ctx, query)
+	result, err := execer.ExecContext(ctx, query, args)
+	finish(err)
+	return result, err
+}
+
+// Ping: Checks the wrapped connection
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// instrumentedStmt: Prepared statement that records the latency of its executions
+type instrumentedStmt struct {
+	stmt  driver.Stmt
+	query string
+}
+
+// Close: Closes the wrapped statement
+func (s *instrumentedStmt) Close() error {
+	return s.stmt.Close()
+}
+
+// NumInput: Returns the number of placeholders of the wrapped statement
+func (s *instrumentedStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+// Exec: Runs the statement
+func (s *instrumentedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	_, finish := startQuery(context.Background(), s.query)
+	result, err := s.stmt.Exec(args)
+	finish(err)
+	return result, err
+}
+
+// Query: Runs the query
+func (s *instrumentedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	_, finish := startQuery(context.Background(), s.query)
+	rows, err := s.stmt.Query(args)
+	finish(err)
+	return rows, err
+}
+
+// startQuery: Auxiliary function that starts measuring a query. A span is only started when the context
+// already has one, so the queries of the untraced background jobs do not create a trace each
+// Params:
+// (ctx): Context of the query
+// (query): SQL statement
+// Return:
+// (context.Context): Context passed to the driver
+// (func(error)): Function called with the error of the query once it returns
+func startQuery(ctx context.Context, query string) (context.Context, func(error)) {
+	start := time.Now()
+	operation, table := describeQuery(query)
+	if trace.SpanFromContext(ctx).SpanContext().IsValid() == false {
+		return ctx, func(error) {
+			metrics.ObserveQuery(operation, table, time.Since(start))
+		}
+	}
+	ctx, span := tracing.Start(ctx, "db "+operation+" "+table,
+		semconv.DBSystemKey.String("cockroachdb"),
+		semconv.DBStatementKey.String(query),
+		semconv.DBOperationKey.String(operation),
+		attribute.String("db.sql.table", table),
+	)
+	return ctx, func(err error) {
+		metrics.ObserveQuery(operation, table, time.Since(start))
+		tracing.End(span, err)
+	}
+}
+
+// describeQuery: Auxiliary function that returns the first keyword of a statement and its main table
+// Params:
+// (query): SQL statement
+// Return:
+// (operation): Keyword in upper case, such as SELECT or UPSERT
+// (table): Table name, empty if the statement has none
+func describeQuery(query string) (operation string, table string) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	operation = strings.ToUpper(fields[0])
+	if match := queryTable.FindStringSubmatch(query); match != nil {
+		table = strings.ToLower(match[1])
+	}
+	return operation, table
+}
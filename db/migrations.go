@@ -0,0 +1,229 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// migrations: Statements executed at startup to create the tables used by the service
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS domains (
+		id SERIAL PRIMARY KEY,
+		servers JSONB NOT NULL,
+		endpoints JSONB NOT NULL,
+		url STRING NOT NULL UNIQUE,
+		sslGrade STRING NOT NULL DEFAULT '',
+		previousSslGrade STRING NOT NULL DEFAULT '',
+		logo STRING NOT NULL DEFAULT '',
+		title STRING NOT NULL DEFAULT '',
+		updatedAt INT8 NOT NULL,
+		serversChanged BOOL NOT NULL DEFAULT false,
+		isDown BOOL NOT NULL DEFAULT false
+	)`,
+	`CREATE TABLE IF NOT EXISTS probes (
+		id SERIAL PRIMARY KEY,
+		domainId INT8 NOT NULL,
+		checkedAt INT8 NOT NULL,
+		isUp BOOL NOT NULL,
+		statusCode INT NOT NULL DEFAULT 0,
+		responseTime INT8 NOT NULL DEFAULT 0,
+		error STRING NOT NULL DEFAULT '',
+		INDEX (domainId, checkedAt)
+	)`,
+	`CREATE TABLE IF NOT EXISTS scan_schedules (
+		domainId INT8 PRIMARY KEY,
+		scanInterval INT8 NOT NULL,
+		nextRunAt INT8 NOT NULL,
+		INDEX (nextRunAt)
+	)`,
+	`CREATE TABLE IF NOT EXISTS webhooks (
+		id SERIAL PRIMARY KEY,
+		url STRING NOT NULL,
+		secret STRING NOT NULL,
+		events JSONB NOT NULL,
+		createdAt INT8 NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id SERIAL PRIMARY KEY,
+		webhookId INT8 NOT NULL,
+		eventType STRING NOT NULL,
+		attempt INT NOT NULL,
+		statusCode INT NOT NULL DEFAULT 0,
+		success BOOL NOT NULL,
+		error STRING NOT NULL DEFAULT '',
+		deliveredAt INT8 NOT NULL,
+		INDEX (webhookId, deliveredAt)
+	)`,
+	`CREATE TABLE IF NOT EXISTS notification_channels (
+		id SERIAL PRIMARY KEY,
+		name STRING NOT NULL,
+		type STRING NOT NULL,
+		target STRING NOT NULL,
+		events JSONB NOT NULL,
+		hosts JSONB NOT NULL,
+		createdAt INT8 NOT NULL
+	)`,
+	`ALTER TABLE domains ADD COLUMN IF NOT EXISTS compliance JSONB`,
+	`CREATE TABLE IF NOT EXISTS policies (
+		id SERIAL PRIMARY KEY,
+		name STRING NOT NULL,
+		type STRING NOT NULL,
+		minGrade STRING NOT NULL DEFAULT '',
+		protocols JSONB NOT NULL,
+		days INT NOT NULL DEFAULT 0
+	)`,
+	`ALTER TABLE domains ADD COLUMN IF NOT EXISTS dns JSONB`,
+	`ALTER TABLE domains ADD COLUMN IF NOT EXISTS headersAudit JSONB`,
+	`ALTER TABLE domains ADD COLUMN IF NOT EXISTS registration JSONB`,
+	`CREATE TABLE IF NOT EXISTS discovered_hosts (
+		id SERIAL PRIMARY KEY,
+		domainId INT8 NOT NULL,
+		name STRING NOT NULL,
+		firstSeenAt INT8 NOT NULL,
+		lastSeenAt INT8 NOT NULL,
+		UNIQUE (domainId, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS batches (
+		id SERIAL PRIMARY KEY,
+		createdAt INT8 NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS batch_hosts (
+		batchId INT8 NOT NULL,
+		seq INT NOT NULL,
+		host STRING NOT NULL,
+		status STRING NOT NULL,
+		error STRING NOT NULL,
+		updatedAt INT8 NOT NULL,
+		PRIMARY KEY (batchId, seq),
+		INDEX (status, batchId, seq)
+	)`,
+	`CREATE TABLE IF NOT EXISTS grade_history (
+		domainId INT8 NOT NULL,
+		scannedAt INT8 NOT NULL,
+		sslGrade STRING NOT NULL,
+		isDown BOOL NOT NULL,
+		PRIMARY KEY (domainId, scannedAt)
+	)`,
+	`CREATE TABLE IF NOT EXISTS domain_tags (
+		domainId INT8 NOT NULL,
+		tag STRING NOT NULL,
+		PRIMARY KEY (domainId, tag),
+		INDEX (tag)
+	)`,
+	`ALTER TABLE policies ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE notification_channels ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'`,
+	`CREATE TABLE IF NOT EXISTS orgs (
+		id SERIAL PRIMARY KEY,
+		name STRING NOT NULL UNIQUE,
+		createdAt INT8 NOT NULL
+	)`,
+	`INSERT INTO orgs (id, name, createdAt) VALUES (1, 'default', 0) ON CONFLICT (id) DO NOTHING`,
+	`ALTER TABLE domains ADD COLUMN IF NOT EXISTS orgId INT8 NOT NULL DEFAULT 1`,
+	`ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS orgId INT8 NOT NULL DEFAULT 1`,
+	`ALTER TABLE notification_channels ADD COLUMN IF NOT EXISTS orgId INT8 NOT NULL DEFAULT 1`,
+	`ALTER TABLE policies ADD COLUMN IF NOT EXISTS orgId INT8 NOT NULL DEFAULT 1`,
+	`ALTER TABLE batches ADD COLUMN IF NOT EXISTS orgId INT8 NOT NULL DEFAULT 1`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS domains_org_url_key ON domains (orgId, url)`,
+	`CREATE TABLE IF NOT EXISTS api_keys (
+		id SERIAL PRIMARY KEY,
+		orgId INT8 NOT NULL,
+		name STRING NOT NULL,
+		prefix STRING NOT NULL,
+		hash STRING NOT NULL UNIQUE,
+		scopes JSONB NOT NULL,
+		createdAt INT8 NOT NULL,
+		lastUsedAt INT8 NOT NULL DEFAULT 0,
+		INDEX (orgId)
+	)`,
+	`CREATE TABLE IF NOT EXISTS audit_events (
+		id SERIAL PRIMARY KEY,
+		orgId INT8 NOT NULL,
+		actor STRING NOT NULL,
+		action STRING NOT NULL,
+		host STRING NOT NULL,
+		method STRING NOT NULL,
+		path STRING NOT NULL,
+		requestId STRING NOT NULL,
+		statusCode INT8 NOT NULL,
+		outcome STRING NOT NULL,
+		occurredAt INT8 NOT NULL,
+		INDEX (orgId, occurredAt),
+		INDEX (orgId, actor, occurredAt)
+	)`,
+	`CREATE TABLE IF NOT EXISTS tag_schedules (
+		orgId INT8 NOT NULL,
+		tag STRING NOT NULL,
+		scanInterval INT8 NOT NULL,
+		PRIMARY KEY (orgId, tag)
+	)`,
+}
+
+// RunMigrations: Executes the migration statements against the database
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (error): Error if a statement fails
+func RunMigrations(db *sql.DB) error {
+	for _, statement := range migrations {
+		if _, err := db.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return dropUrlUniqueIndexes(db)
+}
+
+// urlUniqueIndexesQuery: Selects the unique indexes of the "domains" table whose only key column is url,
+// whatever name the database gave them when the column was declared UNIQUE
+const urlUniqueIndexesQuery = `SELECT index_name FROM information_schema.statistics
+	WHERE table_catalog=current_database() AND table_schema=current_schema() AND table_name='domains' AND non_unique='NO' AND implicit=false AND storing=false
+	GROUP BY index_name HAVING count(*)=1 AND bool_and(column_name='url')`
+
+// dropUrlUniqueIndexes: Auxiliary function that drops the unique indexes on the url of the domains, which the index on
+// (orgId, url) replaces so two organizations can track the same url. It fails if one of them is still present afterwards
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (error): Error if an index cannot be dropped
+func dropUrlUniqueIndexes(db *sql.DB) error {
+	names, err := urlUniqueIndexes(db)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err = db.Exec("DROP INDEX IF EXISTS domains@" + pq.QuoteIdentifier(name) + " CASCADE"); err != nil {
+			return err
+		}
+	}
+	if names, err = urlUniqueIndexes(db); err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("Unique indexes %v on domains.url are still present", names)
+	}
+	return nil
+}
+
+// urlUniqueIndexes: Auxiliary function that lists the unique indexes on the url of the domains
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// ([]string): Names of the indexes
+// (error): Error if the query fails
+func urlUniqueIndexes(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(urlUniqueIndexesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
@@ -4,11 +4,11 @@ import (
 	"database/sql"
 	"fmt"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // StartPostgresqlConnection: Starts a connection to the Cockroach database service
-// and returns a reference to the sql.DB database object.
+// and returns a reference to the sql.DB database object. The latency of every query is recorded in the metrics
 // Params:
 // (userName): Database user name
 // (host): IP or domain where the database is hosted
@@ -17,10 +17,11 @@ import (
 // (*sql.DB): Reference to the sql.DB database object
 // (error): Error if connection fails
 func StartPostgresqlConnection(userName string, host string, dataSourceName string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", fmt.Sprintf("postgresql://%s@%s:26257/%s?sslmode=disable", userName, host, dataSourceName))
+	connector, err := pq.NewConnector(fmt.Sprintf("postgresql://%s@%s:26257/%s?sslmode=disable", userName, host, dataSourceName))
 	if err != nil {
 		return nil, err
 	}
+	db := sql.OpenDB(&instrumentedConnector{base: connector})
 	if err = db.Ping(); err != nil {
 		return nil, err
 	}
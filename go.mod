@@ -4,11 +4,19 @@ go 1.14
 
 require (
 	github.com/AdhityaRamadhanus/fasthttpcors v0.0.0-20170121111917-d4c07198763a
-	github.com/badoux/goscraper v0.0.0-20190827161153-36995ce6b19f
 	github.com/buaazp/fasthttprouter v0.1.1
 	github.com/joho/godotenv v1.3.0
 	github.com/lib/pq v1.7.0
 	github.com/likexian/whois-go v1.7.1
-	github.com/likexian/whois-parser-go v1.14.5 // indirect
+	github.com/likexian/whois-parser-go v1.14.5
+	github.com/miekg/dns v1.1.31
+	github.com/prometheus/client_golang v1.11.1
 	github.com/valyala/fasthttp v1.14.0
+	go.opentelemetry.io/otel v0.20.0
+	go.opentelemetry.io/otel/exporters/otlp v0.20.0
+	go.opentelemetry.io/otel/exporters/stdout v0.20.0
+	go.opentelemetry.io/otel/sdk v0.20.0
+	go.opentelemetry.io/otel/trace v0.20.0
+	golang.org/x/net v0.0.0-20200822124328-c89045814202
+	gopkg.in/yaml.v2 v2.4.0
 )
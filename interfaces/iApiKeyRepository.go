@@ -0,0 +1,14 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IApiKeyRepository...
+type IApiKeyRepository interface {
+	WithOrg(orgID int64) IApiKeyRepository
+	FindByID(ID int64) (*models.ApiKey, error)
+	FindByHash(hash string) (*models.ApiKey, error)
+	GetAll() ([]*models.ApiKey, error)
+	Save(apiKey *models.ApiKey) (int64, error)
+	Delete(ID int64) error
+	TouchLastUsed(ID int64, lastUsedAt int64) error
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IApiKeyService...
+type IApiKeyService interface {
+	WithOrg(orgID int64) IApiKeyService
+	Authenticate(key string) (*models.Principal, error)
+	IssueKey(name string, scopes []string) (*models.ApiKey, error)
+	CreateKey(body []byte) ([]byte, error)
+	GetKeys() ([]byte, error)
+	DeleteKey(ID int64) error
+}
@@ -0,0 +1,11 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IAuditRepository...
+type IAuditRepository interface {
+	WithOrg(orgID int64) IAuditRepository
+	Save(event *models.AuditEvent) (int64, error)
+	Find(actor string, since int64, limit int) ([]*models.AuditEvent, error)
+	DeleteBefore(before int64) (int64, error)
+}
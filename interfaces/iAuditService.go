@@ -0,0 +1,12 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IAuditService...
+type IAuditService interface {
+	WithOrg(orgID int64) IAuditService
+	Start()
+	Record(event *models.AuditEvent) error
+	GetEvents(actor string, since string) ([]byte, error)
+	Prune() (int64, error)
+}
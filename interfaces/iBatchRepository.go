@@ -0,0 +1,13 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IBatchRepository...
+type IBatchRepository interface {
+	WithOrg(orgID int64) IBatchRepository
+	Create(hosts []string, createdAt int64) (int64, error)
+	FindByID(ID int64) (*models.Batch, error)
+	FindQueued(limit int) ([]*models.BatchHost, error)
+	UpdateHost(host *models.BatchHost) error
+	RequeueRunning() error
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+// IBatchService...
+type IBatchService interface {
+	WithOrg(orgID int64) IBatchService
+	CreateBatch(content []byte, format string) ([]byte, error)
+	GetBatch(ID int64) ([]byte, error)
+}
@@ -0,0 +1,7 @@
+package interfaces
+
+// ICertificateService...
+type ICertificateService interface {
+	WithOrg(orgID int64) ICertificateService
+	GetExpiring(days int) ([]byte, error)
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// IChangeDetector...
+type IChangeDetector interface {
+	EndpointsChanged(previous []models.Endpoint, current []models.Endpoint) bool
+	NeedsRefresh(updatedAt int64, endpointsChanged bool, now time.Time) bool
+	ServersChanged(previous []models.Server, current []models.Server) bool
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IChannelRepository...
+type IChannelRepository interface {
+	WithOrg(orgID int64) IChannelRepository
+	FindByID(ID int64) (*models.Channel, error)
+	GetAll() ([]*models.Channel, error)
+	Save(channel *models.Channel) (int64, error)
+	Delete(ID int64) error
+}
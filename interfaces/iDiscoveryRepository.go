@@ -0,0 +1,10 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IDiscoveryRepository...
+type IDiscoveryRepository interface {
+	FindByDomain(domainID int64) ([]*models.DiscoveredHost, error)
+	FindByName(domainID int64, name string) (*models.DiscoveredHost, error)
+	SaveNames(domainID int64, names []string, seenAt int64) error
+}
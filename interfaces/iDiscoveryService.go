@@ -0,0 +1,8 @@
+package interfaces
+
+// IDiscoveryService...
+type IDiscoveryService interface {
+	WithOrg(orgID int64) IDiscoveryService
+	GetDiscovered(hostPath string) ([]byte, error)
+	TrackDiscovered(hostPath string, name string) ([]byte, error)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IDnsCollector...
+type IDnsCollector interface {
+	Collect(host string) (*models.DnsRecords, error)
+}
@@ -1,13 +1,23 @@
 package interfaces
 
-import "github.com/JonatanOrdonez/tr-backend/models"
+import (
+	"context"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
 
 // IDomainRepository...
 type IDomainRepository interface {
+	WithOrg(orgID int64) IDomainRepository
+	WithContext(ctx context.Context) IDomainRepository
 	FindByID(ID int64) (*models.Domain, error)
 	GetAll() ([]*models.Domain, error)
+	FindByTags(tags []string) ([]*models.Domain, error)
+	ForEach(each func(domain *models.Domain) error) error
 	Save(domain *models.Domain) (int64, error)
 	Update(domain *models.Domain) (int64, error)
+	SetDown(ID int64, isDown bool) (bool, error)
 	Delete(ID int) error
 	FindByUrl(url string) (*models.Domain, error)
+	GetGradeHistory(domainID int64, since int64) ([]models.GradePoint, error)
 }
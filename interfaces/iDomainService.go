@@ -1,18 +1,23 @@
 package interfaces
 
 import (
+	"context"
+
 	"github.com/JonatanOrdonez/tr-backend/models"
 	"github.com/valyala/fasthttp"
 )
 
 // IDomainService...
 type IDomainService interface {
+	WithOrg(orgID int64) IDomainService
+	WithContext(ctx context.Context) IDomainService
 	CheckDomainInSsllabs(url string) (*models.Ssllabs, error)
 	FetchServersData(endpoints []models.Endpoint) ([]models.Server, error)
 	ScrapPage(url string) (logo string, title string, err error)
+	FetchPage(url string) (*models.Page, error)
 	RaiseError(ctx *fasthttp.RequestCtx, errorCode int, errorMessage string)
 	EndpointsAreEqual(endpointsA []models.Endpoint, endpointsB []models.Endpoint) bool
 	GetLowerServer(servers []models.Server) (*models.Server, error)
-	GetDomains() ([]byte, error)
+	GetDomains(tags []string) ([]byte, error)
 	CheckDomain(hostPath string) ([]byte, error)
 }
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IEventPublisher...
+type IEventPublisher interface {
+	Publish(event *models.Event)
+}
@@ -0,0 +1,10 @@
+package interfaces
+
+import "io"
+
+// IExportService...
+type IExportService interface {
+	WithOrg(orgID int64) IExportService
+	ValidateExport(format string, level string) error
+	WriteDomains(writer io.Writer, format string, level string) error
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IHostDiscoverer...
+type IHostDiscoverer interface {
+	Discover(domain *models.Domain)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "crypto"
+
+// IJwksSource...
+type IJwksSource interface {
+	Key(kid string) (crypto.PublicKey, error)
+}
@@ -0,0 +1,11 @@
+package interfaces
+
+import "context"
+
+// ILogger...
+type ILogger interface {
+	Debug(ctx context.Context, msg string, keyValues ...interface{})
+	Info(ctx context.Context, msg string, keyValues ...interface{})
+	Warn(ctx context.Context, msg string, keyValues ...interface{})
+	Error(ctx context.Context, msg string, keyValues ...interface{})
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// INotificationService...
+type INotificationService interface {
+	WithOrg(orgID int64) INotificationService
+	Publish(event *models.Event)
+	CreateChannel(body []byte) ([]byte, error)
+	GetChannels() ([]byte, error)
+	DeleteChannel(ID int64) error
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// INotifier...
+type INotifier interface {
+	Notify(target string, event *models.Event) error
+}
@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IOrgRepository...
+type IOrgRepository interface {
+	FindByID(ID int64) (*models.Org, error)
+	Save(org *models.Org) (int64, error)
+}
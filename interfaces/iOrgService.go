@@ -0,0 +1,10 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IOrgService...
+type IOrgService interface {
+	GetOrg(ID int64) ([]byte, error)
+	CreateOrg(name string) (*models.Org, error)
+	CheckOrg(ID int64) error
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IPolicyRepository...
+type IPolicyRepository interface {
+	WithOrg(orgID int64) IPolicyRepository
+	FindByID(ID int64) (*models.Policy, error)
+	GetAll() ([]*models.Policy, error)
+	Save(policy *models.Policy) (int64, error)
+	Delete(ID int64) error
+	ReplaceAll(policies []*models.Policy) error
+}
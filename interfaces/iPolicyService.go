@@ -0,0 +1,19 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IPolicyEvaluator...
+type IPolicyEvaluator interface {
+	Evaluate(domain *models.Domain) *models.Compliance
+}
+
+// IPolicyService...
+type IPolicyService interface {
+	WithOrg(orgID int64) IPolicyService
+	Evaluate(domain *models.Domain) *models.Compliance
+	LoadFile(path string) error
+	CreatePolicy(body []byte) ([]byte, error)
+	GetPolicies() ([]byte, error)
+	DeletePolicy(ID int64) error
+	GetComplianceSummary() ([]byte, error)
+}
@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IProbeRepository...
+type IProbeRepository interface {
+	Save(probe *models.Probe) (int64, error)
+	FindByDomainSince(domainID int64, since int64) ([]*models.Probe, error)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IRateLimiter...
+type IRateLimiter interface {
+	Take(key string) models.RateLimit
+}
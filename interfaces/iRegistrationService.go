@@ -0,0 +1,7 @@
+package interfaces
+
+// IRegistrationService...
+type IRegistrationService interface {
+	WithOrg(orgID int64) IRegistrationService
+	GetExpiring(days int) ([]byte, error)
+}
@@ -0,0 +1,7 @@
+package interfaces
+
+// IReportService...
+type IReportService interface {
+	WithOrg(orgID int64) IReportService
+	GetReport(hostPath string, format string) ([]byte, error)
+}
@@ -0,0 +1,6 @@
+package interfaces
+
+// IScanThrottle...
+type IScanThrottle interface {
+	Wait()
+}
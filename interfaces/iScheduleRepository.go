@@ -0,0 +1,13 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IScheduleRepository...
+type IScheduleRepository interface {
+	FindByDomain(domainID int64) (*models.Schedule, error)
+	FindDue(now int64, limit int) ([]*models.Schedule, error)
+	FindUnscheduled(interval int64) ([]*models.Schedule, error)
+	FindTagInterval(domainID int64) (int64, error)
+	SaveTagInterval(orgID int64, tag string, interval int64) error
+	Save(schedule *models.Schedule) error
+}
@@ -0,0 +1,15 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// ISchedulerService...
+type ISchedulerService interface {
+	WithOrg(orgID int64) ISchedulerService
+	Start()
+	ScheduleDomains() error
+	RunDueScans() error
+	GetSchedule(hostPath string) ([]byte, error)
+	SetInterval(hostPath string, interval string) ([]byte, error)
+	SetTagInterval(tag string, interval string) ([]byte, error)
+	ApplyTagInterval(domain *models.Domain) error
+}
@@ -0,0 +1,10 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IStatsRepository...
+type IStatsRepository interface {
+	WithOrg(orgID int64) IStatsRepository
+	CountByGrade() (map[string]int, error)
+	GetStats(now int64, changedSince int64, top int) (*models.Stats, error)
+}
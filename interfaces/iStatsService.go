@@ -0,0 +1,7 @@
+package interfaces
+
+// IStatsService...
+type IStatsService interface {
+	WithOrg(orgID int64) IStatsService
+	GetStats(days int) ([]byte, error)
+}
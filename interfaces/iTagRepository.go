@@ -0,0 +1,11 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// ITagRepository...
+type ITagRepository interface {
+	WithOrg(orgID int64) ITagRepository
+	GetAll() ([]models.Count, error)
+	Add(domainID int64, tag string) error
+	Remove(domainID int64, tag string) error
+}
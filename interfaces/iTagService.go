@@ -0,0 +1,10 @@
+package interfaces
+
+// ITagService...
+type ITagService interface {
+	WithOrg(orgID int64) ITagService
+	GetTags() ([]byte, error)
+	GetDomainTags(hostPath string) ([]byte, error)
+	AddDomainTags(hostPath string, body []byte) ([]byte, error)
+	RemoveDomainTag(hostPath string, tag string) error
+}
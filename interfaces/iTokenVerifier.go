@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// ITokenVerifier...
+type ITokenVerifier interface {
+	Verify(token string) (*models.Principal, error)
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IUptimeService...
+type IUptimeService interface {
+	WithOrg(orgID int64) IUptimeService
+	Start()
+	ProbeDomains()
+	ProbeDomain(domain *models.Domain) (*models.Probe, error)
+	GetUptime(hostPath string, window string) ([]byte, error)
+}
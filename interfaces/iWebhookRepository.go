@@ -0,0 +1,14 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IWebhookRepository...
+type IWebhookRepository interface {
+	WithOrg(orgID int64) IWebhookRepository
+	FindByID(ID int64) (*models.Webhook, error)
+	GetAll() ([]*models.Webhook, error)
+	Save(webhook *models.Webhook) (int64, error)
+	Delete(ID int64) error
+	SaveDelivery(delivery *models.Delivery) (int64, error)
+	FindDeliveries(webhookID int64, limit int) ([]*models.Delivery, error)
+}
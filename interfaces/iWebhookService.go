@@ -0,0 +1,13 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IWebhookService...
+type IWebhookService interface {
+	WithOrg(orgID int64) IWebhookService
+	Publish(event *models.Event)
+	CreateWebhook(body []byte) ([]byte, error)
+	GetWebhooks() ([]byte, error)
+	DeleteWebhook(ID int64) error
+	GetDeliveries(ID int64) ([]byte, error)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/JonatanOrdonez/tr-backend/models"
+
+// IWhoisCollector...
+type IWhoisCollector interface {
+	Collect(host string, cached *models.Registration) (*models.Registration, error)
+}
@@ -0,0 +1,231 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Level: Severity of a log line
+type Level int
+
+// Levels of the log lines, in increasing severity
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// levelNames: Names of the levels, as printed in the log lines and accepted by ParseLevel
+var levelNames = map[Level]string{LevelDebug: "debug", LevelInfo: "info", LevelWarn: "warn", LevelError: "error"}
+
+// requestIDKey: Key of the context value that stores the id of the request
+type requestIDKey struct{}
+
+// Logger: Leveled logger that writes one line per entry, as a JSON object or as key=value text
+type Logger struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	level  Level
+	json   bool
+	fields []interface{}
+}
+
+// New: Creates a logger
+// Params:
+// (out): Writer of the log lines
+// (format): json or text
+// (level): Lowest level written
+// Return:
+// (*Logger): Reference to the logger
+// (error): Error if the format is unknown
+func New(out io.Writer, format string, level Level) (*Logger, error) {
+	if format != "json" && format != "text" {
+		return nil, fmt.Errorf("Unknown log format %s", format)
+	}
+	return &Logger{out: out, mu: &sync.Mutex{}, level: level, json: format == "json"}, nil
+}
+
+// ParseLevel: Parses the name of a level
+// Params:
+// (name): debug, info, warn or error, in any case
+// Return:
+// (Level): Level
+// (error): Error if the name is unknown
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("Unknown log level %s", name)
+}
+
+// WithRequestID: Returns a copy of a context that carries the id of a request, added to every line logged with it
+// Params:
+// (ctx): Parent context
+// (id): Id of the request
+// Return:
+// (context.Context): Context with the id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID: Returns the id of the request carried by a context
+// Params:
+// (ctx): Context
+// Return:
+// (string): Id of the request, empty if the context has none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// With: Returns a copy of the logger that adds key-value pairs to every line
+// Params:
+// (keyValues): Alternating keys and values
+// Return:
+// (*Logger): Reference to the new logger
+func (l *Logger) With(keyValues ...interface{}) *Logger {
+	scoped := *l
+	scoped.fields = append(append([]interface{}{}, l.fields...), keyValues...)
+	return &scoped
+}
+
+// Debug: Writes a line at the debug level, used for the requests to the upstream services
+func (l *Logger) Debug(ctx context.Context, msg string, keyValues ...interface{}) {
+	l.log(ctx, LevelDebug, msg, keyValues)
+}
+
+// Info: Writes a line at the info level
+func (l *Logger) Info(ctx context.Context, msg string, keyValues ...interface{}) {
+	l.log(ctx, LevelInfo, msg, keyValues)
+}
+
+// Warn: Writes a line at the warn level, used for the failures the process recovers from
+func (l *Logger) Warn(ctx context.Context, msg string, keyValues ...interface{}) {
+	l.log(ctx, LevelWarn, msg, keyValues)
+}
+
+// Error: Writes a line at the error level
+func (l *Logger) Error(ctx context.Context, msg string, keyValues ...interface{}) {
+	l.log(ctx, LevelError, msg, keyValues)
+}
+
+// Fatal: Writes a line at the error level and exits, only used while the service starts
+func (l *Logger) Fatal(ctx context.Context, msg string, keyValues ...interface{}) {
+	l.log(ctx, LevelError, msg, keyValues)
+	os.Exit(1)
+}
+
+// log: Auxiliary function that writes a line if its level is enabled. The line has the time, the level, the message,
+// the request id and the trace id carried by the context, the fields of the logger and the given key-value pairs
+// Params:
+// (ctx): Context of the caller
+// (level): Level of the line
+// (msg): Message
+// (keyValues): Alternating keys and values, a value that is an error is written as its message
+func (l *Logger) log(ctx context.Context, level Level, msg string, keyValues []interface{}) {
+	if level < l.level {
+		return
+	}
+	keys := []string{"time", "level", "msg"}
+	values := map[string]interface{}{"time": time.Now().UTC().Format(time.RFC3339Nano), "level": levelNames[level], "msg": msg}
+	add := func(key string, value interface{}) {
+		if _, ok := values[key]; ok == false {
+			keys = append(keys, key)
+		}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		values[key] = value
+	}
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			add("request_id", id)
+		}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			add("trace_id", spanContext.TraceID().String())
+		}
+	}
+	fields := append(append([]interface{}{}, l.fields...), keyValues...)
+	for i := 0; i < len(fields); i += 2 {
+		key := fmt.Sprint(fields[i])
+		if i+1 == len(fields) {
+			add("!BADKEY", key)
+			break
+		}
+		add(key, fields[i+1])
+	}
+	var line []byte
+	if l.json {
+		line = encodeJSON(keys, values)
+	} else {
+		line = encodeText(keys, values)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(line)
+}
+
+// encodeJSON: Auxiliary function that writes a line as a JSON object, with the keys in the order they were added.
+// A value that cannot be encoded is written as text
+// Params:
+// (keys): Keys of the line, in order
+// (values): Values of the line by key
+// Return:
+// ([]byte): Line, ending with a new line
+func encodeJSON(keys []string, values map[string]interface{}) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		jsonKey, _ := json.Marshal(key)
+		jsonValue, err := json.Marshal(values[key])
+		if err != nil {
+			jsonValue, _ = json.Marshal(fmt.Sprint(values[key]))
+		}
+		buffer.Write(jsonKey)
+		buffer.WriteByte(':')
+		buffer.Write(jsonValue)
+	}
+	buffer.WriteString("}\n")
+	return buffer.Bytes()
+}
+
+// encodeText: Auxiliary function that writes a line as key=value pairs, in the order the keys were added.
+// The values with spaces, quotes or equal signs are quoted
+// Params:
+// (keys): Keys of the line, in order
+// (values): Values of the line by key
+// Return:
+// ([]byte): Line, ending with a new line
+func encodeText(keys []string, values map[string]interface{}) []byte {
+	var builder strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+		value := fmt.Sprint(values[key])
+		if value == "" || strings.ContainsAny(value, " \"=\t\n") {
+			value = strconv.Quote(value)
+		}
+		builder.WriteString(key)
+		builder.WriteByte('=')
+		builder.WriteString(value)
+	}
+	builder.WriteByte('\n')
+	return []byte(builder.String())
+}
@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	cors "github.com/AdhityaRamadhanus/fasthttpcors"
 	controllers "github.com/JonatanOrdonez/tr-backend/controllers"
-	db "github.com/JonatanOrdonez/tr-backend/db"
+	dbPackage "github.com/JonatanOrdonez/tr-backend/db"
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	logging "github.com/JonatanOrdonez/tr-backend/logging"
+	metrics "github.com/JonatanOrdonez/tr-backend/metrics"
+	models "github.com/JonatanOrdonez/tr-backend/models"
 	repositories "github.com/JonatanOrdonez/tr-backend/repositories"
 	"github.com/JonatanOrdonez/tr-backend/services"
+	"github.com/JonatanOrdonez/tr-backend/tracing"
 	fasthttprouter "github.com/buaazp/fasthttprouter"
 	goDotenv "github.com/joho/godotenv"
 	"github.com/valyala/fasthttp"
@@ -18,14 +30,160 @@ import (
 func getServers(ctx *fasthttp.RequestCtx) {
 }
 
+// createOrg: Creates an organization from the command line and prints its id, used with create-api-key to onboard a tenant
+// Params:
+// (orgService): Reference to the orgService interface
+// (args): Arguments of the create-org command: -name
+// Return:
+// (error): Error if the process fails
+func createOrg(orgService interfaces.IOrgService, args []string) error {
+	command := flag.NewFlagSet("create-org", flag.ExitOnError)
+	name := command.String("name", "", "Name of the organization")
+	command.Parse(args)
+	org, err := orgService.CreateOrg(*name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Organization %d (%s) created\n", org.Id, org.Name)
+	return nil
+}
+
+// createApiKey: Issues an API key from the command line and prints it, used to create the first admin key
+// Params:
+// (apiKeyService): Reference to the apiKeyService interface
+// (args): Arguments of the create-api-key command: -org, -name and -scopes
+// Return:
+// (error): Error if the process fails
+func createApiKey(apiKeyService interfaces.IApiKeyService, args []string) error {
+	command := flag.NewFlagSet("create-api-key", flag.ExitOnError)
+	org := command.Int64("org", models.DefaultOrgId, "Id of the organization of the key")
+	name := command.String("name", "", "Name of the key")
+	scopes := command.String("scopes", models.ScopeAdmin, "Comma separated scopes of the key")
+	command.Parse(args)
+	apiKey, err := apiKeyService.WithOrg(*org).IssueKey(*name, strings.Split(*scopes, ","))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("API key %d (%s) issued with scopes %s\n%s\n", apiKey.Id, apiKey.Name, strings.Join(apiKey.Scopes, ","), apiKey.Key)
+	return nil
+}
+
+// parseRoleScopes: Reads the scopes granted to each token role, in the format role=scope,scope;role=scope
+// Params:
+// (value): Value of the JWT_ROLE_SCOPES variable, empty to use the default roles
+// Return:
+// (map[string][]string): Scopes of each role
+// (error): Error if the format or a scope is invalid
+func parseRoleScopes(value string) (map[string][]string, error) {
+	roleScopes := make(map[string][]string)
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("Invalid JWT_ROLE_SCOPES entry %s", entry)
+		}
+		scopes := make([]string, 0)
+		for _, scope := range strings.Split(parts[1], ",") {
+			scope = strings.TrimSpace(scope)
+			known := false
+			for _, knownScope := range models.Scopes {
+				known = known || scope == knownScope
+			}
+			if known == false {
+				return nil, fmt.Errorf("Unknown scope %s in JWT_ROLE_SCOPES", scope)
+			}
+			scopes = append(scopes, scope)
+		}
+		roleScopes[strings.TrimSpace(parts[0])] = scopes
+	}
+	return roleScopes, nil
+}
+
+// parseRate: Reads a rate limit budget in the format requests/period, such as 120/1m
+// Params:
+// (value): Value of the variable, empty to use the default budget
+// (defaultLimit): Default number of requests
+// (defaultPeriod): Default period
+// Return:
+// (int): Number of requests
+// (time.Duration): Period
+// (error): Error if the format is invalid
+func parseRate(value string, defaultLimit int, defaultPeriod time.Duration) (int, time.Duration, error) {
+	if value == "" {
+		return defaultLimit, defaultPeriod, nil
+	}
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("Invalid rate %s", value)
+	}
+	limit, limitErr := strconv.Atoi(parts[0])
+	period, periodErr := time.ParseDuration(parts[1])
+	if limitErr != nil || periodErr != nil || limit <= 0 || period <= 0 {
+		return 0, 0, fmt.Errorf("Invalid rate %s", value)
+	}
+	return limit, period, nil
+}
+
+// parseNetworks: Reads a comma separated list of ip addresses and CIDR networks
+// Params:
+// (value): Value of the variable
+// Return:
+// ([]*net.IPNet): Networks, a single address is a network with one address
+// (error): Error if an entry is invalid
+func parseNetworks(value string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") == false {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid trusted proxy %s", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
 func main() {
 	env := os.Getenv("GO_ENV")
+	var envErr error
 	if env == "dev" {
-		envErr := goDotenv.Load(".env.local")
-		if envErr != nil {
-			fmt.Println("Local variables cannot be loaded")
+		envErr = goDotenv.Load(".env.local")
+	}
+
+	// Init logger...
+	logFormat := os.Getenv("LOG_FORMAT")
+	if logFormat == "" {
+		logFormat = "json"
+		if env == "dev" {
+			logFormat = "text"
 		}
 	}
+	logLevel := logging.LevelInfo
+	if os.Getenv("LOG_LEVEL") != "" {
+		var logLevelErr error
+		if logLevel, logLevelErr = logging.ParseLevel(os.Getenv("LOG_LEVEL")); logLevelErr != nil {
+			log.Fatal(logLevelErr.Error())
+		}
+	}
+	logger, loggerErr := logging.New(os.Stderr, logFormat, logLevel)
+	if loggerErr != nil {
+		log.Fatal(loggerErr.Error())
+	}
+	if envErr != nil {
+		logger.Warn(context.Background(), "Local variables cannot be loaded", "error", envErr)
+	}
 
 	// Init environment variables...
 	dbHost := os.Getenv("DB_HOST")
@@ -33,33 +191,280 @@ func main() {
 	dbUser := os.Getenv("DB_USER")
 	whiteList := os.Getenv("WHITE_LIST")
 	port := os.Getenv("PORT")
+	metricsPort := os.Getenv("METRICS_PORT")
+	if metricsPort == "" {
+		metricsPort = "9090"
+	}
+	uptimeInterval, intervalErr := time.ParseDuration(os.Getenv("UPTIME_INTERVAL"))
+	if intervalErr != nil {
+		uptimeInterval = 5 * time.Minute
+	}
+	smtpHost := os.Getenv("SMTP_HOST")
+	smtpPort := os.Getenv("SMTP_PORT")
+	if smtpPort == "" {
+		smtpPort = "25"
+	}
+	smtpFrom := os.Getenv("SMTP_FROM")
+	smtpUser := os.Getenv("SMTP_USER")
+	smtpPassword := os.Getenv("SMTP_PASSWORD")
+	changeFields := make([]string, 0)
+	if os.Getenv("CHANGE_FIELDS") != "" {
+		changeFields = strings.Split(os.Getenv("CHANGE_FIELDS"), ",")
+	}
+	serversRefreshWindow, refreshWindowErr := time.ParseDuration(os.Getenv("SERVERS_REFRESH_WINDOW"))
+	if refreshWindowErr != nil {
+		serversRefreshWindow = time.Hour
+	}
+	policiesFile := os.Getenv("POLICIES_FILE")
+	dnsNameserver := os.Getenv("DNS_NAMESERVER")
+	whoisTtl, whoisTtlErr := time.ParseDuration(os.Getenv("WHOIS_TTL"))
+	if whoisTtlErr != nil || whoisTtl <= 0 {
+		whoisTtl = 7 * 24 * time.Hour
+	}
+	schedulerEnabled := os.Getenv("SCHEDULER_ENABLED") != "false"
+	discoveryEnabled := os.Getenv("DISCOVERY_ENABLED") == "true"
+	anonymousRead := os.Getenv("ANONYMOUS_READ") == "true"
+	auditRetention, auditRetentionErr := time.ParseDuration(os.Getenv("AUDIT_RETENTION"))
+	if auditRetentionErr != nil || auditRetention <= 0 {
+		auditRetention = 90 * 24 * time.Hour
+	}
+	jwtJwks := os.Getenv("JWT_JWKS")
+	jwtIssuer := os.Getenv("JWT_ISSUER")
+	jwtAudience := os.Getenv("JWT_AUDIENCE")
+	jwtRolesClaim := os.Getenv("JWT_ROLES_CLAIM")
+	if jwtRolesClaim == "" {
+		jwtRolesClaim = "roles"
+	}
+	jwtOrgClaim := os.Getenv("JWT_ORG_CLAIM")
+	if jwtOrgClaim == "" {
+		jwtOrgClaim = "org_id"
+	}
+	readLimit, readPeriod, readRateErr := parseRate(os.Getenv("RATE_LIMIT_READS"), 120, time.Minute)
+	if readRateErr != nil {
+		logger.Fatal(context.Background(), "Startup failed", "error", readRateErr)
+	}
+	scanLimit, scanPeriod, scanRateErr := parseRate(os.Getenv("RATE_LIMIT_SCANS"), 10, time.Hour)
+	if scanRateErr != nil {
+		logger.Fatal(context.Background(), "Startup failed", "error", scanRateErr)
+	}
+	trustedProxies, proxiesErr := parseNetworks(os.Getenv("TRUSTED_PROXIES"))
+	if proxiesErr != nil {
+		logger.Fatal(context.Background(), "Startup failed", "error", proxiesErr)
+	}
+	jwtRoleScopes, roleScopesErr := parseRoleScopes(os.Getenv("JWT_ROLE_SCOPES"))
+	if roleScopesErr != nil {
+		logger.Fatal(context.Background(), "Startup failed", "error", roleScopesErr)
+	}
+	scanInterval := 24 * time.Hour
+	if value := os.Getenv("SCAN_INTERVAL"); value != "" {
+		parsed, scanIntervalErr := time.ParseDuration(value)
+		if scanIntervalErr != nil || parsed < services.MinScanInterval {
+			logger.Fatal(context.Background(), "Startup failed", "error", fmt.Sprintf("SCAN_INTERVAL must be a duration of at least %s", services.MinScanInterval))
+		}
+		scanInterval = parsed
+	}
+	scanSpacing, scanSpacingErr := time.ParseDuration(os.Getenv("SCAN_SPACING"))
+	if scanSpacingErr != nil || scanSpacing <= 0 {
+		scanSpacing = 30 * time.Second
+	}
+
+	// Init tracing...
+	otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if otlpEndpoint == "" {
+		otlpEndpoint = "http://localhost:4318"
+	}
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "tr-backend"
+	}
+	shutdownTracing, tracingErr := tracing.Setup(os.Getenv("OTEL_TRACES_EXPORTER"), otlpEndpoint, serviceName)
+	if tracingErr != nil {
+		logger.Fatal(context.Background(), "Startup failed", "error", tracingErr)
+	}
 
 	// Init database...
-	db, err := db.StartPostgresqlConnection(dbUser, dbHost, dbName)
+	db, err := dbPackage.StartPostgresqlConnection(dbUser, dbHost, dbName)
 	if err != nil {
-		log.Fatal(err.Error())
+		logger.Fatal(context.Background(), "Startup failed", "error", err)
 	} else {
+		if err := dbPackage.RunMigrations(db); err != nil {
+			logger.Fatal(context.Background(), "Startup failed", "error", err)
+		}
+
 		// Init repositories...
-		domainRepo := repositories.NewDomainRepository(db)
-		domainService := services.NewDomainService(domainRepo)
-		domainController := controllers.NewDomainController(domainService)
+		domainRepo := repositories.NewDomainRepository(db, logger)
+		probeRepo := repositories.NewProbeRepository(db)
+		scheduleRepo := repositories.NewScheduleRepository(db)
+		webhookRepo := repositories.NewWebhookRepository(db)
+		channelRepo := repositories.NewChannelRepository(db)
+		policyRepo := repositories.NewPolicyRepository(db)
+		batchRepo := repositories.NewBatchRepository(db)
+		statsRepo := repositories.NewStatsRepository(db)
+		tagRepo := repositories.NewTagRepository(db)
+		discoveryRepo := repositories.NewDiscoveryRepository(db)
+		orgRepo := repositories.NewOrgRepository(db)
+		apiKeyRepo := repositories.NewApiKeyRepository(db)
+		auditRepo := repositories.NewAuditRepository(db)
+
+		// Init notifiers...
+		notifierClient := &http.Client{Timeout: 10 * time.Second}
+		notifiers := map[string]interfaces.INotifier{
+			models.ChannelSlack: services.NewSlackNotifier(notifierClient),
+			models.ChannelTeams: services.NewTeamsNotifier(notifierClient),
+		}
+		if smtpHost != "" {
+			notifiers[models.ChannelEmail] = services.NewSmtpNotifier(smtpHost, smtpPort, smtpFrom, smtpUser, smtpPassword)
+		}
+
+		// Init services...
+		changeDetector, detectorErr := services.NewChangeDetector(changeFields, serversRefreshWindow)
+		if detectorErr != nil {
+			logger.Fatal(context.Background(), "Startup failed", "error", detectorErr)
+		}
+		policyService := services.NewPolicyService(policyRepo, domainRepo)
+		if policiesFile != "" {
+			if err := policyService.WithOrg(models.DefaultOrgId).LoadFile(policiesFile); err != nil {
+				logger.Fatal(context.Background(), "Startup failed", "error", err)
+			}
+		}
+		dnsCollector, dnsErr := services.NewDnsCollector(dnsNameserver)
+		if dnsErr != nil {
+			logger.Fatal(context.Background(), "Startup failed", "error", dnsErr)
+		}
+		whoisCollector := services.NewWhoisCollector(whoisTtl)
+		hostDiscoverer := services.NewHostDiscoverer(discoveryRepo, discoveryEnabled, logger)
+		webhookService := services.NewWebhookService(webhookRepo)
+		certificateService := services.NewCertificateService(domainRepo)
+		registrationService := services.NewRegistrationService(domainRepo)
+		exportService := services.NewExportService(domainRepo)
+		reportService := services.NewReportService(domainRepo)
+		statsService := services.NewStatsService(statsRepo)
+		notificationService := services.NewNotificationService(channelRepo, notifiers, logger)
+		eventBroadcaster := services.NewEventBroadcaster(webhookService, notificationService)
+		domainService := services.NewDomainService(domainRepo, eventBroadcaster, changeDetector, policyService, dnsCollector, whoisCollector, hostDiscoverer, logger)
+		uptimeService := services.NewUptimeService(domainRepo, probeRepo, eventBroadcaster, uptimeInterval, logger)
+		scanThrottle := services.NewScanThrottle(scanSpacing)
+		schedulerService := services.NewSchedulerService(domainRepo, scheduleRepo, domainService, scanThrottle, scanInterval, scanSpacing, logger)
+		tagService := services.NewTagService(domainRepo, tagRepo, schedulerService)
+		batchService := services.NewBatchService(batchRepo, domainService, scanThrottle, logger)
+		discoveryService := services.NewDiscoveryService(domainRepo, discoveryRepo, domainService)
+		orgService := services.NewOrgService(orgRepo)
+		apiKeyService := services.NewApiKeyService(apiKeyRepo, orgService)
+		auditService := services.NewAuditService(auditRepo, auditRetention, logger)
+		var tokenVerifier interfaces.ITokenVerifier
+		if jwtJwks != "" {
+			if jwtIssuer == "" || jwtAudience == "" {
+				logger.Fatal(context.Background(), "JWT_ISSUER and JWT_AUDIENCE are required when JWT_JWKS is set")
+			}
+			jwksSource, jwksErr := services.NewJwksSource(jwtJwks)
+			if jwksErr != nil {
+				logger.Fatal(context.Background(), "Startup failed", "error", jwksErr)
+			}
+			tokenVerifier = services.NewTokenVerifier(jwksSource, jwtIssuer, jwtAudience, jwtRolesClaim, jwtOrgClaim, jwtRoleScopes)
+		}
+		if len(os.Args) > 1 && os.Args[1] == "create-org" {
+			if err := createOrg(orgService, os.Args[2:]); err != nil {
+				logger.Fatal(context.Background(), "Organization cannot be created", "error", err)
+			}
+			return
+		}
+		if len(os.Args) > 1 && os.Args[1] == "create-api-key" {
+			if err := createApiKey(apiKeyService, os.Args[2:]); err != nil {
+				logger.Fatal(context.Background(), "API key cannot be created", "error", err)
+			}
+			return
+		}
+		domainController := controllers.NewDomainController(domainService, logger)
+		uptimeController := controllers.NewUptimeController(uptimeService)
+		scheduleController := controllers.NewScheduleController(schedulerService)
+		webhookController := controllers.NewWebhookController(webhookService)
+		channelController := controllers.NewChannelController(notificationService)
+		policyController := controllers.NewPolicyController(policyService)
+		certificateController := controllers.NewCertificateController(certificateService)
+		registrationController := controllers.NewRegistrationController(registrationService)
+		discoveryController := controllers.NewDiscoveryController(discoveryService)
+		batchController := controllers.NewBatchController(batchService)
+		exportController := controllers.NewExportController(exportService, logger)
+		reportController := controllers.NewReportController(reportService)
+		statsController := controllers.NewStatsController(statsService)
+		tagController := controllers.NewTagController(tagService)
+		orgController := controllers.NewOrgController(orgService)
+		apiKeyController := controllers.NewApiKeyController(apiKeyService)
+		audit := controllers.NewAuditController(auditService, logger)
+
+		// Init background jobs...
+		uptimeService.Start()
+		batchService.Start()
+		auditService.Start()
+		if schedulerEnabled {
+			schedulerService.Start()
+		}
 
 		// Init router...
+		rateLimit := controllers.NewRateLimitMiddleware(services.NewRateLimiter(readLimit, readPeriod), services.NewRateLimiter(scanLimit, scanPeriod), trustedProxies)
 		router := fasthttprouter.New()
-		router.GET("/api/v1/analyze", domainController.ResponseCheckDomain)
+		route := func(method string, path string, handler fasthttp.RequestHandler) {
+			router.Handle(method, path, metrics.Route(path, handler))
+		}
+		route("GET", "/api/v1/analyze", rateLimit.Analyze(audit.Analyze(controllers.RequireScope(models.ScopeDomainsRead, domainController.ResponseCheckDomain))))
+		route("POST", "/api/v1/analyze/bulk", rateLimit.Scans(audit.Record("batch.create", controllers.RequireScope(models.ScopeScansTrigger, batchController.ResponseCreateBatch))))
+		route("GET", "/api/v1/batches/:id", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, batchController.ResponseBatch)))
+		route("GET", "/api/v1/domains/:host", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, exportController.ResponseExport)))
+		route("GET", "/api/v1/domains/:host/uptime", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, uptimeController.ResponseUptime)))
+		route("GET", "/api/v1/domains/:host/schedule", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, scheduleController.ResponseSchedule)))
+		route("PUT", "/api/v1/domains/:host/schedule", rateLimit.Reads(audit.Record("schedule.update", controllers.RequireScope(models.ScopeDomainsWrite, scheduleController.ResponseSetSchedule))))
+		route("GET", "/api/v1/domains/:host/tags", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, tagController.ResponseDomainTags)))
+		route("POST", "/api/v1/domains/:host/tags", rateLimit.Reads(audit.Record("tags.add", controllers.RequireScope(models.ScopeDomainsWrite, tagController.ResponseAddDomainTags))))
+		route("DELETE", "/api/v1/domains/:host/tags/:tag", rateLimit.Reads(audit.Record("tags.remove", controllers.RequireScope(models.ScopeDomainsWrite, tagController.ResponseRemoveDomainTag))))
+		route("GET", "/api/v1/tags", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, tagController.ResponseTags)))
+		route("PUT", "/api/v1/tags/:tag/schedule", rateLimit.Reads(audit.Record("tag_schedule.update", controllers.RequireScope(models.ScopeDomainsWrite, scheduleController.ResponseSetTagSchedule))))
+		route("GET", "/api/v1/domains/:host/report", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, reportController.ResponseReport)))
+		route("GET", "/api/v1/domains/:host/discovered", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, discoveryController.ResponseDiscovered)))
+		route("POST", "/api/v1/domains/:host/discovered/:name/track", rateLimit.Scans(audit.Record("discovered.track", controllers.RequireScope(models.ScopeScansTrigger, discoveryController.ResponseTrack))))
+		route("GET", "/api/v1/webhooks", rateLimit.Reads(controllers.RequireScope(models.ScopeAdmin, webhookController.ResponseWebhooks)))
+		route("POST", "/api/v1/webhooks", rateLimit.Reads(audit.Record("webhook.create", controllers.RequireScope(models.ScopeAdmin, webhookController.ResponseCreateWebhook))))
+		route("DELETE", "/api/v1/webhooks/:id", rateLimit.Reads(audit.Record("webhook.delete", controllers.RequireScope(models.ScopeAdmin, webhookController.ResponseDeleteWebhook))))
+		route("GET", "/api/v1/webhooks/:id/deliveries", rateLimit.Reads(controllers.RequireScope(models.ScopeAdmin, webhookController.ResponseDeliveries)))
+		route("GET", "/api/v1/channels", rateLimit.Reads(controllers.RequireScope(models.ScopeAdmin, channelController.ResponseChannels)))
+		route("POST", "/api/v1/channels", rateLimit.Reads(audit.Record("channel.create", controllers.RequireScope(models.ScopeAdmin, channelController.ResponseCreateChannel))))
+		route("DELETE", "/api/v1/channels/:id", rateLimit.Reads(audit.Record("channel.delete", controllers.RequireScope(models.ScopeAdmin, channelController.ResponseDeleteChannel))))
+		route("GET", "/api/v1/policies", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, policyController.ResponsePolicies)))
+		route("POST", "/api/v1/policies", rateLimit.Reads(audit.Record("policy.create", controllers.RequireScope(models.ScopeAdmin, policyController.ResponseCreatePolicy))))
+		route("DELETE", "/api/v1/policies/:id", rateLimit.Reads(audit.Record("policy.delete", controllers.RequireScope(models.ScopeAdmin, policyController.ResponseDeletePolicy))))
+		route("GET", "/api/v1/compliance", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, policyController.ResponseCompliance)))
+		route("GET", "/api/v1/stats", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, statsController.ResponseStats)))
+		route("GET", "/api/v1/certificates/expiring", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, certificateController.ResponseExpiring)))
+		route("GET", "/api/v1/registrations/expiring", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, registrationController.ResponseExpiring)))
+		route("GET", "/api/v1/org", rateLimit.Reads(controllers.RequireScope(models.ScopeDomainsRead, orgController.ResponseOrg)))
+		route("GET", "/api/v1/keys", rateLimit.Reads(controllers.RequireScope(models.ScopeAdmin, apiKeyController.ResponseKeys)))
+		route("POST", "/api/v1/keys", rateLimit.Reads(audit.Record("key.create", controllers.RequireScope(models.ScopeAdmin, apiKeyController.ResponseCreateKey))))
+		route("DELETE", "/api/v1/keys/:id", rateLimit.Reads(audit.Record("key.delete", controllers.RequireScope(models.ScopeAdmin, apiKeyController.ResponseDeleteKey))))
+		route("GET", "/api/v1/audit", rateLimit.Reads(controllers.RequireScope(models.ScopeAdmin, audit.ResponseAudit)))
 
 		withCors := cors.NewCorsHandler(cors.Options{
 			AllowedOrigins:   []string{whiteList},
-			AllowedHeaders:   []string{"x-something-client", "Content-Type"},
-			AllowedMethods:   []string{"GET"},
+			AllowedHeaders:   []string{"x-something-client", "Content-Type", "X-API-Key", "Authorization", "traceparent", "tracestate"},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
 			AllowCredentials: false,
 			AllowMaxAge:      5600,
 			Debug:            true,
 		})
 
-		fmt.Println("Starting server...")
-		if err := fasthttp.ListenAndServe(":"+port, withCors.CorsMiddleware(router.Handler)); err != nil {
-			log.Fatalf("Error in ListenAndServe: %s", err.Error())
+		if err := metrics.RegisterDomainGrades(statsRepo.CountByGrade); err != nil {
+			logger.Fatal(context.Background(), "Metrics cannot be registered", "error", err)
+		}
+
+		// The metrics are served on their own port, which is not meant to be exposed outside the network of the scraper
+		go func() {
+			if err := fasthttp.ListenAndServe(":"+metricsPort, metrics.Handler()); err != nil {
+				logger.Fatal(context.Background(), "Metrics server stopped", "error", err)
+			}
+		}()
+
+		logger.Info(context.Background(), "Starting server", "port", port, "metricsPort", metricsPort)
+		if err := fasthttp.ListenAndServe(":"+port, metrics.Middleware(tracing.Middleware(controllers.RequestID(withCors.CorsMiddleware(controllers.Authenticate(router.Handler, apiKeyService, tokenVerifier, orgService, anonymousRead)), logger)))); err != nil {
+			shutdownTracing(context.Background())
+			logger.Fatal(context.Background(), "Server stopped", "error", err)
 		}
 	}
 }
@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// routeKey: Key of the user value that stores the route pattern of a request
+const routeKey = "route"
+
+// unmatchedRoute: Route label of the requests that did not reach a registered route
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by route, method and status code.",
+	}, []string{"route", "method", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of the HTTP requests, by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+	ssllabsRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ssllabs_requests_total",
+		Help: "Calls to the SSL Labs API, by assessment status, HTTP status code or error.",
+	}, []string{"status"})
+	ssllabsDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ssllabs_request_duration_seconds",
+		Help:    "Latency of the calls to the SSL Labs API, by assessment status, HTTP status code or error.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"status"})
+	whoisDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "whois_lookup_duration_seconds",
+		Help:    "Latency of the WHOIS lookups, by kind: ip for the servers and domain for the registrations.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"kind"})
+	whoisFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "whois_lookup_failures_total",
+		Help: "Failed WHOIS lookups, by kind.",
+	}, []string{"kind"})
+	scraperRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scraper_requests_total",
+		Help: "Fetches of the home pages, by outcome.",
+	}, []string{"outcome"})
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Latency of the database queries, by operation and table.",
+		Buckets: []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"operation", "table"})
+	scansInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scans_in_flight",
+		Help: "Domain scans running.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequests, httpDuration, ssllabsRequests, ssllabsDuration, whoisDuration, whoisFailures, scraperRequests, dbDuration, scansInFlight)
+}
+
+// Handler: Returns the handler of the metrics server, in the Prometheus text format
+// Return:
+// (fasthttp.RequestHandler): Handler
+func Handler() fasthttp.RequestHandler {
+	return fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler())
+}
+
+// Middleware: Measures every request. The route label is set by Route, so the requests rejected
+// before the router, or not found, share the unmatched label instead of creating a series per path
+// Params:
+// (next): Handler of the request
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func Middleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+		next(ctx)
+		route := RouteOf(ctx)
+		method := string(ctx.Method())
+		httpRequests.WithLabelValues(route, method, strconv.Itoa(ctx.Response.StatusCode())).Inc()
+		httpDuration.WithLabelValues(route, method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Route: Stores the route pattern of the request, used as the route label by Middleware
+// Params:
+// (route): Route pattern, such as /api/v1/domains/:host
+// (next): Handler of the request
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func Route(route string, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		ctx.SetUserValue(routeKey, route)
+		next(ctx)
+	}
+}
+
+// RouteOf: Returns the route pattern stored by Route, or unmatched if the request did not reach a registered route
+// Params:
+// (ctx): Request reference
+// Return:
+// (string): Route pattern
+func RouteOf(ctx *fasthttp.RequestCtx) string {
+	route, ok := ctx.UserValue(routeKey).(string)
+	if ok == false {
+		return unmatchedRoute
+	}
+	return route
+}
+
+// ObserveSsllabs: Records a call to the SSL Labs API
+// Params:
+// (status): Assessment status, such as READY, ERROR or DNS, the HTTP status code if it is not 200, such as 429, network_error if the call failed or invalid_response
+// (duration): Latency of the call
+func ObserveSsllabs(status string, duration time.Duration) {
+	ssllabsRequests.WithLabelValues(status).Inc()
+	ssllabsDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
+
+// ObserveWhois: Records a WHOIS lookup
+// Params:
+// (kind): ip or domain
+// (duration): Latency of the lookup
+// (err): Error of the lookup, nil if it succeeded
+func ObserveWhois(kind string, duration time.Duration, err error) {
+	whoisDuration.WithLabelValues(kind).Observe(duration.Seconds())
+	if err != nil {
+		whoisFailures.WithLabelValues(kind).Inc()
+	}
+}
+
+// ObserveScraper: Records the fetch of a home page
+// Params:
+// (outcome): success, empty, http_error, network_error or invalid_url
+func ObserveScraper(outcome string) {
+	scraperRequests.WithLabelValues(outcome).Inc()
+}
+
+// ObserveQuery: Records a database query
+// Params:
+// (operation): First keyword of the statement, such as SELECT
+// (table): Main table of the statement
+// (duration): Latency of the query
+func ObserveQuery(operation string, table string, duration time.Duration) {
+	dbDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+}
+
+// ScanStarted: Counts a scan as running. ScanFinished must be called when it ends
+func ScanStarted() {
+	scansInFlight.Inc()
+}
+
+// ScanFinished: Stops counting a scan as running
+func ScanFinished() {
+	scansInFlight.Dec()
+}
+
+// gradeCollector: Collector that counts the tracked domains by grade each time the metrics are scraped
+type gradeCollector struct {
+	desc  *prometheus.Desc
+	count func() (map[string]int, error)
+}
+
+// Describe: Sends the description of the tracked domains gauge
+func (c *gradeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect: Counts the domains and sends a gauge by grade. Nothing is sent if the count fails
+func (c *gradeCollector) Collect(ch chan<- prometheus.Metric) {
+	grades, err := c.count()
+	if err != nil {
+		return
+	}
+	for grade, count := range grades {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), grade)
+	}
+}
+
+// RegisterDomainGrades: Registers the tracked_domains gauge, by grade
+// Params:
+// (count): Function that counts the domains of every organization by grade
+// Return:
+// (error): Error if the gauge is already registered
+func RegisterDomainGrades(count func() (map[string]int, error)) error {
+	return prometheus.Register(&gradeCollector{
+		desc:  prometheus.NewDesc("tracked_domains", "Tracked domains, by SSL grade.", []string{"grade"}, nil),
+		count: count,
+	})
+}
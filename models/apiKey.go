@@ -0,0 +1,26 @@
+package models
+
+// API key scopes
+const (
+	ScopeDomainsRead  = "domains:read"
+	ScopeDomainsWrite = "domains:write"
+	ScopeScansTrigger = "scans:trigger"
+	ScopeAdmin        = "admin"
+)
+
+// Scopes: Every scope an API key can be granted
+var Scopes = []string{ScopeDomainsRead, ScopeDomainsWrite, ScopeScansTrigger, ScopeAdmin}
+
+// ApiKey entity...
+// Only the SHA-256 hash of the key is stored, the key itself is returned once, when it is issued
+type ApiKey struct {
+	Id         int64    `db:"id" json:"id"`
+	OrgId      int64    `db:"orgId" json:"-"`
+	Name       string   `db:"name" json:"name"`
+	Prefix     string   `db:"prefix" json:"prefix"`
+	Hash       string   `db:"hash" json:"-"`
+	Scopes     []string `db:"scopes" json:"scopes"`
+	CreatedAt  int64    `db:"createdAt" json:"created_at"`
+	LastUsedAt int64    `db:"lastUsedAt" json:"last_used_at"`
+	Key        string   `db:"-" json:"key,omitempty"`
+}
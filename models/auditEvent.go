@@ -0,0 +1,23 @@
+package models
+
+// Audit outcomes
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEvent entity...
+// Record of a request that changed, or tried to change, the data of an organization
+type AuditEvent struct {
+	Id         int64  `db:"id" json:"id"`
+	OrgId      int64  `db:"orgId" json:"-"`
+	Actor      string `db:"actor" json:"actor"`
+	Action     string `db:"action" json:"action"`
+	Host       string `db:"host" json:"host"`
+	Method     string `db:"method" json:"method"`
+	Path       string `db:"path" json:"path"`
+	RequestId  string `db:"requestId" json:"request_id"`
+	StatusCode int    `db:"statusCode" json:"status_code"`
+	Outcome    string `db:"outcome" json:"outcome"`
+	OccurredAt int64  `db:"occurredAt" json:"occurred_at"`
+}
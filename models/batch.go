@@ -0,0 +1,48 @@
+package models
+
+// Batch host statuses...
+const (
+	BatchQueued  = "queued"
+	BatchRunning = "running"
+	BatchDone    = "done"
+	BatchFailed  = "failed"
+)
+
+// Bulk input formats...
+const (
+	BulkJSON = "json"
+	BulkCSV  = "csv"
+	BulkText = "text"
+)
+
+// Batch entity...
+type Batch struct {
+	Id        int64          `db:"id" json:"id"`
+	OrgId     int64          `db:"orgId" json:"-"`
+	CreatedAt int64          `db:"createdAt" json:"created_at"`
+	Status    string         `db:"-" json:"status"`
+	Total     int            `db:"-" json:"total"`
+	Queued    int            `db:"-" json:"queued"`
+	Running   int            `db:"-" json:"running"`
+	Done      int            `db:"-" json:"done"`
+	Failed    int            `db:"-" json:"failed"`
+	Hosts     []*BatchHost   `db:"-" json:"hosts"`
+	Rejected  []RejectedHost `db:"-" json:"rejected,omitempty"`
+}
+
+// BatchHost entity...
+type BatchHost struct {
+	BatchId   int64  `db:"batchId" json:"-"`
+	OrgId     int64  `db:"orgId" json:"-"`
+	Position  int    `db:"seq" json:"-"`
+	Host      string `db:"host" json:"host"`
+	Status    string `db:"status" json:"status"`
+	Error     string `db:"error" json:"error,omitempty"`
+	UpdatedAt int64  `db:"updatedAt" json:"updated_at"`
+}
+
+// RejectedHost entity...
+type RejectedHost struct {
+	Host   string `json:"host"`
+	Reason string `json:"reason"`
+}
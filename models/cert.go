@@ -0,0 +1,13 @@
+package models
+
+// Cert entity...
+type Cert struct {
+	Id            string   `json:"id"`
+	Subject       string   `json:"subject"`
+	SerialNumber  string   `json:"serialNumber"`
+	CommonNames   []string `json:"commonNames"`
+	AltNames      []string `json:"altNames"`
+	NotBefore     int64    `json:"notBefore"`
+	NotAfter      int64    `json:"notAfter"`
+	IssuerSubject string   `json:"issuerSubject"`
+}
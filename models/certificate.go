@@ -0,0 +1,19 @@
+package models
+
+// Certificate entity...
+type Certificate struct {
+	Subject   string   `json:"subject"`
+	Sans      []string `json:"sans"`
+	Issuer    string   `json:"issuer"`
+	NotBefore int64    `json:"not_before"`
+	NotAfter  int64    `json:"not_after"`
+	Serial    string   `json:"serial"`
+}
+
+// ExpiringCertificate entity...
+type ExpiringCertificate struct {
+	Url         string       `json:"url"`
+	Address     string       `json:"address"`
+	DaysLeft    int          `json:"days_left"`
+	Certificate *Certificate `json:"certificate"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// Channel types...
+const (
+	ChannelEmail = "email"
+	ChannelSlack = "slack"
+	ChannelTeams = "teams"
+)
+
+// Channel entity...
+type Channel struct {
+	Id        int64    `db:"id" json:"id"`
+	OrgId     int64    `db:"orgId" json:"-"`
+	Name      string   `db:"name" json:"name"`
+	Type      string   `db:"type" json:"type"`
+	Target    string   `db:"target" json:"target"`
+	Events    []string `db:"events" json:"events"`
+	Hosts     []string `db:"hosts" json:"hosts"`
+	Tags      []string `db:"tags" json:"tags"`
+	CreatedAt int64    `db:"createdAt" json:"created_at"`
+}
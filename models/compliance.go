@@ -0,0 +1,30 @@
+package models
+
+// Compliance entity...
+type Compliance struct {
+	Compliant   bool         `json:"compliant"`
+	EvaluatedAt int64        `json:"evaluated_at"`
+	Results     []RuleResult `json:"results"`
+}
+
+// RuleResult entity...
+type RuleResult struct {
+	Policy  string   `json:"policy"`
+	Type    string   `json:"type"`
+	Passed  bool     `json:"passed"`
+	Reasons []string `json:"reasons"`
+}
+
+// ComplianceSummary entity...
+type ComplianceSummary struct {
+	Domains   int          `json:"domains"`
+	Compliant int          `json:"compliant"`
+	Violating int          `json:"violating"`
+	Violators []*Violation `json:"violators"`
+}
+
+// Violation entity...
+type Violation struct {
+	Url    string       `json:"url"`
+	Failed []RuleResult `json:"failed"`
+}
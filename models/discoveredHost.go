@@ -0,0 +1,11 @@
+package models
+
+// DiscoveredHost entity...
+type DiscoveredHost struct {
+	Id          int64  `db:"id" json:"-"`
+	DomainId    int64  `db:"domainId" json:"-"`
+	Name        string `db:"name" json:"name"`
+	FirstSeenAt int64  `db:"firstSeenAt" json:"first_seen_at"`
+	LastSeenAt  int64  `db:"lastSeenAt" json:"last_seen_at"`
+	Tracked     bool   `db:"-" json:"tracked"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// DnsRecords entity...
+type DnsRecords struct {
+	A           []string    `json:"a"`
+	AAAA        []string    `json:"aaaa"`
+	CnameChain  []string    `json:"cname_chain"`
+	MX          []MxRecord  `json:"mx"`
+	NS          []string    `json:"ns"`
+	CAA         []CaaRecord `json:"caa"`
+	TXT         []string    `json:"txt"`
+	SPF         string      `json:"spf"`
+	DMARC       string      `json:"dmarc"`
+	CollectedAt int64       `json:"collected_at"`
+	Changed     bool        `json:"changed"`
+	Changes     []string    `json:"changes"`
+}
+
+// MxRecord entity...
+type MxRecord struct {
+	Host       string `json:"host"`
+	Preference uint16 `json:"preference"`
+}
+
+// CaaRecord entity...
+type CaaRecord struct {
+	Flag  uint8  `json:"flag"`
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
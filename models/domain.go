@@ -2,15 +2,21 @@ package models
 
 // Domain entity...
 type Domain struct {
-	Servers          []Server   `db:"servers" json:"servers"`
-	Endpoints        []Endpoint `db:"endpoints" json:"-"`
-	ServersChanged   bool       `db:"serversChanged" json:"servers_changed"`
-	SslGrade         string     `db:"url" json:"ssl_grade"`
-	PreviousSslGrade string     `db:"previousSslGrade" json:"previous_ssl_grade"`
-	Logo             string     `db:"logo" json:"logo"`
-	Title            string     `db:"title" json:"title"`
-	IsDown           bool       `db:"isDown" json:"is_down"`
-	Id               int64      `db:"id" json:"-"`
-	Url              string     `db:"url" json:"url"`
-	UpdatedAt        int64      `db:"updatedAt" json:"-"`
+	Servers          []Server      `db:"servers" json:"servers"`
+	Endpoints        []Endpoint    `db:"endpoints" json:"-"`
+	ServersChanged   bool          `db:"serversChanged" json:"servers_changed"`
+	SslGrade         Grade         `db:"sslGrade" json:"ssl_grade"`
+	PreviousSslGrade Grade         `db:"previousSslGrade" json:"previous_ssl_grade"`
+	Logo             string        `db:"logo" json:"logo"`
+	Title            string        `db:"title" json:"title"`
+	IsDown           bool          `db:"isDown" json:"is_down"`
+	Id               int64         `db:"id" json:"-"`
+	OrgId            int64         `db:"orgId" json:"-"`
+	Url              string        `db:"url" json:"url"`
+	UpdatedAt        int64         `db:"updatedAt" json:"-"`
+	Compliance       *Compliance   `db:"compliance" json:"compliance"`
+	Dns              *DnsRecords   `db:"dns" json:"dns"`
+	Headers          *HeadersAudit `db:"headersAudit" json:"headers"`
+	Registration     *Registration `db:"registration" json:"registration"`
+	Tags             []string      `db:"tags" json:"tags"`
 }
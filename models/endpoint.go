@@ -2,16 +2,37 @@ package models
 
 // Endpoint entity...
 type Endpoint struct {
-	IpAddress            string `json:"ipAddress"`
-	ServerName           string `json:"serverName"`
-	StatusMessage        string `json:"statusMessage"`
-	Grade                string `json:"grade"`
-	GradeTrustIgnored    string `json:"gradeTrustIgnored"`
-	HasWarnings          bool   `json:"hasWarnings"`
-	IsExceptional        bool   `json:"isExceptional"`
-	Progress             int    `json:"progress"`
-	Duration             int    `json:"duration"`
-	StatusDetails        string `json:"statusDetails"`
-	StatusDetailsMessage string `json:"statusDetailsMessage"`
-	Delegation           int    `json:"delegation"`
+	IpAddress            string           `json:"ipAddress"`
+	ServerName           string           `json:"serverName"`
+	StatusMessage        string           `json:"statusMessage"`
+	Grade                Grade            `json:"grade"`
+	GradeTrustIgnored    Grade            `json:"gradeTrustIgnored"`
+	HasWarnings          bool             `json:"hasWarnings"`
+	IsExceptional        bool             `json:"isExceptional"`
+	Progress             int              `json:"progress"`
+	Duration             int              `json:"duration"`
+	StatusDetails        string           `json:"statusDetails"`
+	StatusDetailsMessage string           `json:"statusDetailsMessage"`
+	Delegation           int              `json:"delegation"`
+	Details              *EndpointDetails `json:"details,omitempty"`
+	Certificate          *Cert            `json:"certificate,omitempty"`
+}
+
+// EndpointDetails entity...
+type EndpointDetails struct {
+	Protocols  []Protocol  `json:"protocols"`
+	CertChains []CertChain `json:"certChains"`
+}
+
+// Protocol entity...
+type Protocol struct {
+	Id      int    `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// CertChain entity...
+type CertChain struct {
+	Id      string   `json:"id"`
+	CertIds []string `json:"certIds"`
 }
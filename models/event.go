@@ -0,0 +1,21 @@
+package models
+
+// Event types...
+const (
+	EventGradeDowngraded = "grade.downgraded"
+	EventGradeUpgraded   = "grade.upgraded"
+	EventServersChanged  = "servers.changed"
+	EventDomainDown      = "domain.down"
+	EventDomainUp        = "domain.up"
+)
+
+// EventTypes: Every event type a subscriber can filter by
+var EventTypes = []string{EventGradeDowngraded, EventGradeUpgraded, EventServersChanged, EventDomainDown, EventDomainUp}
+
+// Event entity...
+type Event struct {
+	Type       string  `json:"type"`
+	Url        string  `json:"url"`
+	OccurredAt int64   `json:"occurred_at"`
+	Domain     *Domain `json:"domain"`
+}
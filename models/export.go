@@ -0,0 +1,34 @@
+package models
+
+// Export formats and levels...
+const (
+	ExportCSV         = "csv"
+	ExportNDJSON      = "ndjson"
+	ExportDomainLevel = "domain"
+	ExportServerLevel = "server"
+)
+
+// DomainExport entity...
+type DomainExport struct {
+	Url              string   `json:"url"`
+	SslGrade         Grade    `json:"ssl_grade"`
+	PreviousSslGrade Grade    `json:"previous_ssl_grade"`
+	IsDown           bool     `json:"is_down"`
+	ServersChanged   bool     `json:"servers_changed"`
+	UpdatedAt        string   `json:"updated_at"`
+	Servers          []Server `json:"servers"`
+}
+
+// ServerExport entity...
+type ServerExport struct {
+	Url              string `json:"url"`
+	SslGrade         Grade  `json:"ssl_grade"`
+	PreviousSslGrade Grade  `json:"previous_ssl_grade"`
+	IsDown           bool   `json:"is_down"`
+	ServersChanged   bool   `json:"servers_changed"`
+	UpdatedAt        string `json:"updated_at"`
+	Address          string `json:"address"`
+	ServerSslGrade   Grade  `json:"server_ssl_grade"`
+	Country          string `json:"country"`
+	Owner            string `json:"owner"`
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Grade entity...
+// SSL Labs grade of a server or domain. The zero value means "not graded"
+type Grade string
+
+// Grades...
+const (
+	GradeNotGraded    Grade = ""
+	GradeAPlus        Grade = "A+"
+	GradeA            Grade = "A"
+	GradeAMinus       Grade = "A-"
+	GradeB            Grade = "B"
+	GradeC            Grade = "C"
+	GradeD            Grade = "D"
+	GradeE            Grade = "E"
+	GradeF            Grade = "F"
+	GradeTrustIssues  Grade = "T"
+	GradeNameMismatch Grade = "M"
+)
+
+// gradeRanks: Position of each grade in the total ordering, the higher the better.
+// T (certificate not trusted) and M (certificate name mismatch) are considered worse than F,
+// and "not graded" is below every real grade
+var gradeRanks = map[Grade]int{
+	GradeNotGraded:    0,
+	GradeNameMismatch: 1,
+	GradeTrustIssues:  2,
+	GradeF:            3,
+	GradeE:            4,
+	GradeD:            5,
+	GradeC:            6,
+	GradeB:            7,
+	GradeAMinus:       8,
+	GradeA:            9,
+	GradeAPlus:        10,
+}
+
+// ParseGrade: Converts a string into a grade, ignoring surrounding spaces and case
+// Params:
+// (value): Grade as a string, empty for "not graded"
+// Return:
+// (Grade): Parsed grade
+// (error): Error if the value is not a known grade
+func ParseGrade(value string) (Grade, error) {
+	grade := Grade(strings.ToUpper(strings.TrimSpace(value)))
+	if _, ok := gradeRanks[grade]; ok == false {
+		return GradeNotGraded, fmt.Errorf("Unknown grade %s", value)
+	}
+	return grade, nil
+}
+
+// IsGraded: Checks if the grade is a real grade
+// Return:
+// (bool): False if the grade is "not graded"
+func (g Grade) IsGraded() bool {
+	return g != GradeNotGraded
+}
+
+// Rank: Position of the grade in the total ordering, the higher the better
+// Return:
+// (int): Rank of the grade, 0 for "not graded" or unknown grades
+func (g Grade) Rank() int {
+	return gradeRanks[g]
+}
+
+// Compare: Compares two grades
+// Params:
+// (other): Grade to compare with
+// Return:
+// (int): Negative if g is worse than other, 0 if they are equal, positive if g is better
+func (g Grade) Compare(other Grade) int {
+	return g.Rank() - other.Rank()
+}
+
+// MarshalJSON: Encodes the grade as a JSON string
+// Return:
+// ([]byte): JSON string
+// (error): Error if the process fails
+func (g Grade) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(g))
+}
+
+// UnmarshalJSON: Decodes and normalizes a JSON string into a grade. The grades come from SSL Labs, so a value
+// that is not a known grade is decoded as "not graded" instead of failing the whole response
+// Params:
+// (data): JSON string, null or empty for "not graded"
+// Return:
+// (error): Error if the value is not a JSON string
+func (g *Grade) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == nil {
+		*g = GradeNotGraded
+		return nil
+	}
+	grade, err := ParseGrade(*value)
+	if err != nil {
+		grade = GradeNotGraded
+	}
+	*g = grade
+	return nil
+}
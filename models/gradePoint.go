@@ -0,0 +1,8 @@
+package models
+
+// GradePoint entity...
+type GradePoint struct {
+	ScannedAt int64 `db:"scannedAt" json:"scanned_at"`
+	SslGrade  Grade `db:"sslGrade" json:"ssl_grade"`
+	IsDown    bool  `db:"isDown" json:"is_down"`
+}
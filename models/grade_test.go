@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseGrade(t *testing.T) {
+	cases := []struct {
+		value   string
+		want    Grade
+		wantErr bool
+	}{
+		{"A+", GradeAPlus, false},
+		{" a+ ", GradeAPlus, false},
+		{"a", GradeA, false},
+		{"A-", GradeAMinus, false},
+		{"b", GradeB, false},
+		{"F", GradeF, false},
+		{"t", GradeTrustIssues, false},
+		{"M", GradeNameMismatch, false},
+		{"", GradeNotGraded, false},
+		{"  ", GradeNotGraded, false},
+		{"A++", GradeNotGraded, true},
+		{"G", GradeNotGraded, true},
+		{"not graded", GradeNotGraded, true},
+	}
+	for _, c := range cases {
+		got, err := ParseGrade(c.value)
+		if (err != nil) != c.wantErr {
+			t.Errorf("ParseGrade(%q) error = %v, want error %v", c.value, err, c.wantErr)
+		}
+		if got != c.want {
+			t.Errorf("ParseGrade(%q) = %q, want %q", c.value, got, c.want)
+		}
+	}
+}
+
+func TestGradeOrdering(t *testing.T) {
+	ordered := []Grade{GradeAPlus, GradeA, GradeAMinus, GradeB, GradeC, GradeD, GradeE, GradeF, GradeTrustIssues, GradeNameMismatch, GradeNotGraded}
+	for i := 0; i+1 < len(ordered); i++ {
+		better, worse := ordered[i], ordered[i+1]
+		if better.Rank() <= worse.Rank() {
+			t.Errorf("Rank(%q) = %d, want above Rank(%q) = %d", better, better.Rank(), worse, worse.Rank())
+		}
+	}
+	cases := []struct {
+		a, b Grade
+		want int
+	}{
+		{GradeAPlus, GradeA, 1},
+		{GradeA, GradeAPlus, -1},
+		{GradeAMinus, GradeB, 1},
+		{GradeF, GradeTrustIssues, 1},
+		{GradeF, GradeNameMismatch, 1},
+		{GradeTrustIssues, GradeNotGraded, 1},
+		{GradeNameMismatch, GradeNotGraded, 1},
+		{GradeNotGraded, GradeF, -1},
+		{GradeB, GradeB, 0},
+		{GradeNotGraded, GradeNotGraded, 0},
+	}
+	for _, c := range cases {
+		got := c.a.Compare(c.b)
+		if sign(got) != c.want {
+			t.Errorf("%q.Compare(%q) = %d, want sign %d", c.a, c.b, got, c.want)
+		}
+	}
+	if Grade("Z").Rank() != 0 {
+		t.Errorf("unknown grades must rank as not graded")
+	}
+}
+
+func TestGradeMarshalJSON(t *testing.T) {
+	cases := []struct {
+		grade Grade
+		want  string
+	}{
+		{GradeAPlus, `"A+"`},
+		{GradeAMinus, `"A-"`},
+		{GradeTrustIssues, `"T"`},
+		{GradeNotGraded, `""`},
+	}
+	for _, c := range cases {
+		got, err := json.Marshal(c.grade)
+		if err != nil {
+			t.Fatalf("Marshal(%q): %v", c.grade, err)
+		}
+		if string(got) != c.want {
+			t.Errorf("Marshal(%q) = %s, want %s", c.grade, got, c.want)
+		}
+	}
+}
+
+func TestGradeUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		data    string
+		want    Grade
+		wantErr bool
+	}{
+		{`"A+"`, GradeAPlus, false},
+		{`"a-"`, GradeAMinus, false},
+		{`" b "`, GradeB, false},
+		{`"m"`, GradeNameMismatch, false},
+		{`""`, GradeNotGraded, false},
+		{`null`, GradeNotGraded, false},
+		{`"Z"`, GradeNotGraded, false},
+		{`"A++"`, GradeNotGraded, false},
+		{`7`, GradeNotGraded, true},
+	}
+	for _, c := range cases {
+		var got Grade
+		err := json.Unmarshal([]byte(c.data), &got)
+		if (err != nil) != c.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, want error %v", c.data, err, c.wantErr)
+		}
+		if got != c.want {
+			t.Errorf("Unmarshal(%s) = %q, want %q", c.data, got, c.want)
+		}
+	}
+	var endpoint Endpoint
+	if err := json.Unmarshal([]byte(`{"ipAddress":"10.0.0.1","grade":"X","gradeTrustIgnored":"A"}`), &endpoint); err != nil {
+		t.Fatalf("Unmarshal of an endpoint with an unknown grade: %v", err)
+	}
+	if endpoint.Grade != GradeNotGraded || endpoint.GradeTrustIgnored != GradeA || endpoint.IpAddress != "10.0.0.1" {
+		t.Errorf("Unmarshal of an endpoint with an unknown grade = %+v", endpoint)
+	}
+}
+
+func sign(value int) int {
+	switch {
+	case value > 0:
+		return 1
+	case value < 0:
+		return -1
+	}
+	return 0
+}
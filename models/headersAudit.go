@@ -0,0 +1,24 @@
+package models
+
+// Header audit statuses...
+const (
+	HeaderPass = "pass"
+	HeaderWarn = "warn"
+	HeaderFail = "fail"
+)
+
+// HeadersAudit entity...
+type HeadersAudit struct {
+	Grade     Grade          `json:"grade"`
+	Score     int            `json:"score"`
+	CheckedAt int64          `json:"checked_at"`
+	Headers   []HeaderResult `json:"headers"`
+}
+
+// HeaderResult entity...
+type HeaderResult struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// DefaultOrgId: Id of the organization that owns the records created before the organizations existed
+const DefaultOrgId int64 = 1
+
+// Org entity...
+type Org struct {
+	Id        int64  `db:"id" json:"id"`
+	Name      string `db:"name" json:"name"`
+	CreatedAt int64  `db:"createdAt" json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "net/http"
+
+// Page entity...
+type Page struct {
+	Url        string
+	StatusCode int
+	Headers    http.Header
+	Logo       string
+	Title      string
+}
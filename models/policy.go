@@ -0,0 +1,26 @@
+package models
+
+// Policy types...
+const (
+	PolicyMinGrade           = "min_grade"
+	PolicyForbiddenProtocols = "forbidden_protocols"
+	PolicyCertificateMinDays = "certificate_min_days"
+	PolicyUniformGrade       = "uniform_grade"
+)
+
+// Policy entity...
+type Policy struct {
+	Id        int64    `db:"id" json:"id" yaml:"-"`
+	OrgId     int64    `db:"orgId" json:"-" yaml:"-"`
+	Name      string   `db:"name" json:"name" yaml:"name"`
+	Type      string   `db:"type" json:"type" yaml:"type"`
+	MinGrade  Grade    `db:"minGrade" json:"min_grade,omitempty" yaml:"min_grade"`
+	Protocols []string `db:"protocols" json:"protocols,omitempty" yaml:"protocols"`
+	Days      int      `db:"days" json:"days,omitempty" yaml:"days"`
+	Tags      []string `db:"tags" json:"tags" yaml:"tags"`
+}
+
+// PolicyFile entity...
+type PolicyFile struct {
+	Policies []*Policy `yaml:"policies"`
+}
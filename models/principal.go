@@ -0,0 +1,23 @@
+package models
+
+// Principal entity...
+// Identity that makes a request, the organization whose data it can see and the scopes it was granted
+type Principal struct {
+	Subject string   `json:"subject"`
+	OrgId   int64    `json:"org_id"`
+	Scopes  []string `json:"scopes"`
+}
+
+// HasScope: Checks if the principal was granted a scope. The admin scope grants every scope
+// Params:
+// (scope): Scope to be checked
+// Return:
+// (bool): True if the scope was granted. False if not
+func (p *Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package models
+
+// Probe entity...
+type Probe struct {
+	Id           int64  `db:"id" json:"-"`
+	DomainId     int64  `db:"domainId" json:"-"`
+	CheckedAt    int64  `db:"checkedAt" json:"checked_at"`
+	IsUp         bool   `db:"isUp" json:"is_up"`
+	StatusCode   int    `db:"statusCode" json:"status_code"`
+	ResponseTime int64  `db:"responseTime" json:"response_time"`
+	Error        string `db:"error" json:"error"`
+}
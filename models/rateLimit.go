@@ -0,0 +1,11 @@
+package models
+
+// RateLimit entity...
+// Outcome of taking a token from the bucket of a client
+type RateLimit struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter int64
+	Reset      int64
+}
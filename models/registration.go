@@ -0,0 +1,19 @@
+package models
+
+// Registration entity...
+type Registration struct {
+	Domain      string   `json:"domain"`
+	Registrar   string   `json:"registrar"`
+	CreatedAt   int64    `json:"created_at"`
+	ExpiresAt   int64    `json:"expires_at"`
+	NameServers []string `json:"name_servers"`
+	Status      []string `json:"status"`
+	CheckedAt   int64    `json:"checked_at"`
+}
+
+// ExpiringRegistration entity...
+type ExpiringRegistration struct {
+	Url          string        `json:"url"`
+	DaysLeft     int           `json:"days_left"`
+	Registration *Registration `json:"registration"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// Schedule entity...
+type Schedule struct {
+	DomainId  int64 `db:"domainId" json:"-"`
+	Interval  int64 `db:"scanInterval" json:"interval"`
+	NextRunAt int64 `db:"nextRunAt" json:"next_run_at"`
+}
@@ -2,8 +2,9 @@ package models
 
 // Server entity...
 type Server struct {
-	Address  string `json:"address"`
-	SslGrade string `json:"ssl_grade"`
-	Country  string `json:"country"`
-	Owner    string `json:"owner"`
+	Address     string       `json:"address"`
+	SslGrade    Grade        `json:"ssl_grade"`
+	Country     string       `json:"country"`
+	Owner       string       `json:"owner"`
+	Certificate *Certificate `json:"certificate,omitempty"`
 }
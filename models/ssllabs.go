@@ -14,4 +14,5 @@ type Ssllabs struct {
 	StatusMessage   string     `json:"statusMessage"`
 	CacheExpiryTime int64      `json:"cacheExpiryTime"`
 	Endpoints       []Endpoint `json:"endpoints"`
+	Certs           []Cert     `json:"certs"`
 }
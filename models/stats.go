@@ -0,0 +1,20 @@
+package models
+
+// Stats entity...
+type Stats struct {
+	Domains            int            `json:"domains"`
+	GradeDistribution  map[string]int `json:"grade_distribution"`
+	Down               int            `json:"down"`
+	ServersChanged     int            `json:"servers_changed"`
+	ServersChangedDays int            `json:"servers_changed_days"`
+	TopOwners          []Count        `json:"top_owners"`
+	TopCountries       []Count        `json:"top_countries"`
+	AverageScanAge     int64          `json:"average_scan_age"`
+	GeneratedAt        int64          `json:"generated_at"`
+}
+
+// Count entity...
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
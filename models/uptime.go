@@ -0,0 +1,17 @@
+package models
+
+// Uptime entity...
+type Uptime struct {
+	Url              string     `json:"url"`
+	Window           string     `json:"window"`
+	Availability     float64    `json:"availability"`
+	MeanResponseTime float64    `json:"mean_response_time"`
+	Probes           int        `json:"probes"`
+	Incidents        []Incident `json:"incidents"`
+}
+
+// Incident entity...
+type Incident struct {
+	DownFrom int64 `json:"down_from"`
+	DownTo   int64 `json:"down_to"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// Webhook entity...
+type Webhook struct {
+	Id        int64    `db:"id" json:"id"`
+	OrgId     int64    `db:"orgId" json:"-"`
+	Url       string   `db:"url" json:"url"`
+	Secret    string   `db:"secret" json:"secret,omitempty"`
+	Events    []string `db:"events" json:"events"`
+	CreatedAt int64    `db:"createdAt" json:"created_at"`
+}
+
+// Delivery entity...
+type Delivery struct {
+	Id          int64  `db:"id" json:"id"`
+	WebhookId   int64  `db:"webhookId" json:"webhook_id"`
+	EventType   string `db:"eventType" json:"event_type"`
+	Attempt     int    `db:"attempt" json:"attempt"`
+	StatusCode  int    `db:"statusCode" json:"status_code"`
+	Success     bool   `db:"success" json:"success"`
+	Error       string `db:"error" json:"error"`
+	DeliveredAt int64  `db:"deliveredAt" json:"delivered_at"`
+}
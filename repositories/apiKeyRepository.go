@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// apiKeyColumns: Columns read by scanApiKey, in order
+const apiKeyColumns = "id, orgId, name, prefix, hash, scopes, createdAt, lastUsedAt"
+
+// ApiKeyRepo: Structure used to store the database access reference
+type ApiKeyRepo struct {
+	db    *sql.DB
+	orgID int64
+}
+
+// NewApiKeyRepository: Receives a reference to the database and stores it in the ApiKeyRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*ApiKeyRepo): Reference to the ApiKeyRepo object
+func NewApiKeyRepository(db *sql.DB) *ApiKeyRepo {
+	return &ApiKeyRepo{db: db, orgID: unscopedOrg}
+}
+
+// WithOrg: Returns a copy of the repository whose queries only see the records of an organization.
+// The repository returned by NewApiKeyRepository is not scoped and is only used to authenticate the requests
+// Params:
+// (orgID): Id of the organization, an id that is not positive yields a repository that matches no record
+// Return:
+// (interfaces.IApiKeyRepository): Scoped repository
+func (r *ApiKeyRepo) WithOrg(orgID int64) interfaces.IApiKeyRepository {
+	return &ApiKeyRepo{db: r.db, orgID: scopeOrg(orgID)}
+}
+
+// FindByID: Searchs for an API key in the database using its id property as a search criteria
+// Params:
+// (ID): Id of the API key you are looking for
+// Return:
+// (*models.ApiKey): Reference to the API key that was found
+// (error): Error if the process fails
+func (r *ApiKeyRepo) FindByID(ID int64) (*models.ApiKey, error) {
+	row := r.db.QueryRow("SELECT "+apiKeyColumns+" FROM api_keys WHERE id=$1 AND ($2=0 OR orgId=$2)", ID, r.orgID)
+	return scanApiKey(row)
+}
+
+// FindByHash: Searchs for an API key in the database using the hash of the key as a search criteria
+// Params:
+// (hash): SHA-256 hash of the key, hex encoded
+// Return:
+// (*models.ApiKey): Reference to the API key that was found
+// (error): Error if the process fails
+func (r *ApiKeyRepo) FindByHash(hash string) (*models.ApiKey, error) {
+	row := r.db.QueryRow("SELECT "+apiKeyColumns+" FROM api_keys WHERE hash=$1 AND ($2=0 OR orgId=$2)", hash, r.orgID)
+	return scanApiKey(row)
+}
+
+// GetAll: Gets all the records that are in the "api_keys" table
+// Return:
+// ([]*models.ApiKey): reference to the API key slice
+// (error): Error if the process fails
+func (r *ApiKeyRepo) GetAll() ([]*models.ApiKey, error) {
+	rows, err := r.db.Query("SELECT "+apiKeyColumns+" FROM api_keys WHERE ($1=0 OR orgId=$1) ORDER BY id", r.orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	apiKeys := make([]*models.ApiKey, 0)
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+// Save: Stores a new API key in the database
+// Params:
+// (apiKey): Reference to the API key object to be stored
+// Return:
+// (int64): Id of the stored API key
+// (error): Error if the process fails
+func (r *ApiKeyRepo) Save(apiKey *models.ApiKey) (int64, error) {
+	id := int64(-1)
+	jsonScopes, jScopesError := json.Marshal(apiKey.Scopes)
+	if jScopesError != nil {
+		return id, jScopesError
+	}
+	owner, ownerErr := ownerOrg(r.orgID, apiKey.OrgId)
+	if ownerErr != nil {
+		return id, ownerErr
+	}
+	queryErr := r.db.QueryRow(`INSERT INTO api_keys (orgId, name, prefix, hash, scopes, createdAt, lastUsedAt) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, owner, apiKey.Name, apiKey.Prefix, apiKey.Hash, jsonScopes, apiKey.CreatedAt, apiKey.LastUsedAt).Scan(&id)
+	if queryErr != nil {
+		return id, queryErr
+	}
+	return id, nil
+}
+
+// Delete: Remove an API key from the database
+// Params:
+// (ID): Id of the API key you want to remove
+// Return:
+// (error): Error if the process fails
+func (r *ApiKeyRepo) Delete(ID int64) error {
+	_, err := r.db.Exec("DELETE FROM api_keys WHERE id=$1 AND ($2=0 OR orgId=$2)", ID, r.orgID)
+	return err
+}
+
+// TouchLastUsed: Updates the moment an API key was last used
+// Params:
+// (ID): Id of the API key
+// (lastUsedAt): Unix time of the use
+// Return:
+// (error): Error if the process fails
+func (r *ApiKeyRepo) TouchLastUsed(ID int64, lastUsedAt int64) error {
+	_, err := r.db.Exec("UPDATE api_keys SET lastUsedAt=$1 WHERE id=$2 AND ($3=0 OR orgId=$3)", lastUsedAt, ID, r.orgID)
+	return err
+}
+
+// scanApiKey: Auxiliary function that reads an API key from a row
+// Params:
+// (row): Row or rows reference positioned on the record
+// Return:
+// (*models.ApiKey): Reference to the API key
+// (error): Error if the process fails
+func scanApiKey(row interface{ Scan(...interface{}) error }) (*models.ApiKey, error) {
+	apiKey := &models.ApiKey{}
+	var scopes []byte
+	if err := row.Scan(&apiKey.Id, &apiKey.OrgId, &apiKey.Name, &apiKey.Prefix, &apiKey.Hash, &scopes, &apiKey.CreatedAt, &apiKey.LastUsedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopes, &apiKey.Scopes); err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"database/sql"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// AuditRepo: Structure used to store the database access reference.
+// The audit log is append-only: events are never updated, and only deleted by the retention job
+type AuditRepo struct {
+	db    *sql.DB
+	orgID int64
+}
+
+// NewAuditRepository: Receives a reference to the database and stores it in the AuditRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*AuditRepo): Reference to the AuditRepo object
+func NewAuditRepository(db *sql.DB) *AuditRepo {
+	return &AuditRepo{db: db, orgID: unscopedOrg}
+}
+
+// WithOrg: Returns a copy of the repository whose queries only see the records of an organization.
+// The repository returned by NewAuditRepository is not scoped and is only used by the background jobs
+// Params:
+// (orgID): Id of the organization, an id that is not positive yields a repository that matches no record
+// Return:
+// (interfaces.IAuditRepository): Scoped repository
+func (r *AuditRepo) WithOrg(orgID int64) interfaces.IAuditRepository {
+	return &AuditRepo{db: r.db, orgID: scopeOrg(orgID)}
+}
+
+// Save: Appends an event to the audit log
+// Params:
+// (event): Reference to the event to be stored
+// Return:
+// (int64): Id of the stored event
+// (error): Error if the process fails
+func (r *AuditRepo) Save(event *models.AuditEvent) (int64, error) {
+	id := int64(-1)
+	owner, ownerErr := ownerOrg(r.orgID, event.OrgId)
+	if ownerErr != nil {
+		return id, ownerErr
+	}
+	queryErr := r.db.QueryRow(`INSERT INTO audit_events (orgId, actor, action, host, method, path, requestId, statusCode, outcome, occurredAt) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`, owner, event.Actor, event.Action, event.Host, event.Method, event.Path, event.RequestId, event.StatusCode, event.Outcome, event.OccurredAt).Scan(&id)
+	if queryErr != nil {
+		return id, queryErr
+	}
+	return id, nil
+}
+
+// Find: Gets the events that occurred since a moment, the newest first
+// Params:
+// (actor): Actor of the events, every actor if empty
+// (since): Unix time of the oldest event
+// (limit): Maximum number of events returned
+// Return:
+// ([]*models.AuditEvent): Reference to the event slice
+// (error): Error if the process fails
+func (r *AuditRepo) Find(actor string, since int64, limit int) ([]*models.AuditEvent, error) {
+	rows, err := r.db.Query(`SELECT id, orgId, actor, action, host, method, path, requestId, statusCode, outcome, occurredAt FROM audit_events
+		WHERE ($1=0 OR orgId=$1) AND ($2='' OR actor=$2) AND occurredAt>=$3 ORDER BY occurredAt DESC, id DESC LIMIT $4`, r.orgID, actor, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := make([]*models.AuditEvent, 0)
+	for rows.Next() {
+		event := &models.AuditEvent{}
+		if err := rows.Scan(&event.Id, &event.OrgId, &event.Actor, &event.Action, &event.Host, &event.Method, &event.Path, &event.RequestId, &event.StatusCode, &event.Outcome, &event.OccurredAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// DeleteBefore: Removes the events older than a moment
+// Params:
+// (before): Unix time of the oldest event kept
+// Return:
+// (int64): Number of removed events
+// (error): Error if the process fails
+func (r *AuditRepo) DeleteBefore(before int64) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM audit_events WHERE ($1=0 OR orgId=$1) AND occurredAt<$2", r.orgID, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"database/sql"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// BatchRepo: Structure used to store the database access reference
+type BatchRepo struct {
+	db    *sql.DB
+	orgID int64
+}
+
+// NewBatchRepository: Receives a reference to the database and stores it in the BatchRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*BatchRepo): Reference to the BatchRepo object
+func NewBatchRepository(db *sql.DB) *BatchRepo {
+	return &BatchRepo{db: db, orgID: unscopedOrg}
+}
+
+// WithOrg: Returns a copy of the repository whose queries only see the records of an organization.
+// The repository returned by NewBatchRepository is not scoped and is only used by the background jobs
+// Params:
+// (orgID): Id of the organization, an id that is not positive yields a repository that matches no record
+// Return:
+// (interfaces.IBatchRepository): Scoped repository
+func (r *BatchRepo) WithOrg(orgID int64) interfaces.IBatchRepository {
+	return &BatchRepo{db: r.db, orgID: scopeOrg(orgID)}
+}
+
+// Create: Stores, in a single transaction, a new batch of the organization with its hosts queued in the given order
+// Params:
+// (hosts): Hosts of the batch
+// (createdAt): Unix time of the creation
+// Return:
+// (int64): Id of the stored batch
+// (error): Error if the process fails
+func (r *BatchRepo) Create(hosts []string, createdAt int64) (int64, error) {
+	id := int64(-1)
+	owner, ownerErr := ownerOrg(r.orgID, unscopedOrg)
+	if ownerErr != nil {
+		return id, ownerErr
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return id, err
+	}
+	if err = tx.QueryRow("INSERT INTO batches (orgId, createdAt) VALUES ($1, $2) RETURNING id", owner, createdAt).Scan(&id); err != nil {
+		tx.Rollback()
+		return id, err
+	}
+	for position, host := range hosts {
+		_, err = tx.Exec("INSERT INTO batch_hosts (batchId, seq, host, status, error, updatedAt) VALUES ($1, $2, $3, $4, '', $5)", id, position, host, models.BatchQueued, createdAt)
+		if err != nil {
+			tx.Rollback()
+			return id, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+// FindByID: Searchs for a batch and its hosts, in the order they were submitted
+// Params:
+// (ID): Id of the batch
+// Return:
+// (*models.Batch): Reference to the batch that was found
+// (error): Error if the process fails
+func (r *BatchRepo) FindByID(ID int64) (*models.Batch, error) {
+	batch := &models.Batch{}
+	if err := r.db.QueryRow("SELECT id, orgId, createdAt FROM batches WHERE id=$1 AND ($2=0 OR orgId=$2)", ID, r.orgID).Scan(&batch.Id, &batch.OrgId, &batch.CreatedAt); err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query("SELECT batchId, seq, host, status, error, updatedAt FROM batch_hosts WHERE batchId=$1 ORDER BY seq", ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	batch.Hosts = make([]*models.BatchHost, 0)
+	for rows.Next() {
+		host := &models.BatchHost{}
+		if err := rows.Scan(&host.BatchId, &host.Position, &host.Host, &host.Status, &host.Error, &host.UpdatedAt); err != nil {
+			return nil, err
+		}
+		batch.Hosts = append(batch.Hosts, host)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// FindQueued: Gets the queued hosts, the oldest batch first, with the organization of their batch
+// Params:
+// (limit): Maximum number of hosts returned
+// Return:
+// ([]*models.BatchHost): Reference to the batch host slice
+// (error): Error if the process fails
+func (r *BatchRepo) FindQueued(limit int) ([]*models.BatchHost, error) {
+	rows, err := r.db.Query("SELECT batch_hosts.batchId, batch_hosts.seq, batch_hosts.host, batch_hosts.status, batch_hosts.error, batch_hosts.updatedAt, batches.orgId FROM batch_hosts JOIN batches ON batches.id=batch_hosts.batchId WHERE batch_hosts.status=$1 AND ($3=0 OR batches.orgId=$3) ORDER BY batch_hosts.batchId, batch_hosts.seq LIMIT $2", models.BatchQueued, limit, r.orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	hosts := make([]*models.BatchHost, 0)
+	for rows.Next() {
+		host := &models.BatchHost{}
+		if err := rows.Scan(&host.BatchId, &host.Position, &host.Host, &host.Status, &host.Error, &host.UpdatedAt, &host.OrgId); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+// UpdateHost: Updates the status of a host of a batch
+// Params:
+// (host): Reference to the batch host
+// Return:
+// (error): Error if the process fails
+func (r *BatchRepo) UpdateHost(host *models.BatchHost) error {
+	_, err := r.db.Exec("UPDATE batch_hosts SET status=$1, error=$2, updatedAt=$3 WHERE batchId=$4 AND seq=$5", host.Status, host.Error, host.UpdatedAt, host.BatchId, host.Position)
+	return err
+}
+
+// RequeueRunning: Queues again the hosts that were running, used on start up after a restart interrupted them
+// Return:
+// (error): Error if the process fails
+func (r *BatchRepo) RequeueRunning() error {
+	_, err := r.db.Exec("UPDATE batch_hosts SET status=$1 WHERE status=$2", models.BatchQueued, models.BatchRunning)
+	return err
+}
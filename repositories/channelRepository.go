@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// ChannelRepo: Structure used to store the database access reference
+type ChannelRepo struct {
+	db    *sql.DB
+	orgID int64
+}
+
+// NewChannelRepository: Receives a reference to the database and stores it in the ChannelRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*ChannelRepo): Reference to the ChannelRepo object
+func NewChannelRepository(db *sql.DB) *ChannelRepo {
+	return &ChannelRepo{db: db, orgID: unscopedOrg}
+}
+
+// WithOrg: Returns a copy of the repository whose queries only see the records of an organization.
+// The repository returned by NewChannelRepository is not scoped and is only used by the background jobs
+// Params:
+// (orgID): Id of the organization, an id that is not positive yields a repository that matches no record
+// Return:
+// (interfaces.IChannelRepository): Scoped repository
+func (r *ChannelRepo) WithOrg(orgID int64) interfaces.IChannelRepository {
+	return &ChannelRepo{db: r.db, orgID: scopeOrg(orgID)}
+}
+
+// FindByID: Searchs for a notification channel in the database using its id property as a search criteria
+// Params:
+// (ID): Id of the channel you are looking for
+// Return:
+// (*models.Channel): Reference to the channel that was found
+// (error): Error if the process fails
+func (r *ChannelRepo) FindByID(ID int64) (*models.Channel, error) {
+	row := r.db.QueryRow("SELECT id, orgId, name, type, target, events, hosts, tags, createdAt FROM notification_channels WHERE id=$1 AND ($2=0 OR orgId=$2)", ID, r.orgID)
+	return scanChannel(row)
+}
+
+// GetAll: Gets all the records that are in the "notification_channels" table
+// Return:
+// ([]*models.Channel): reference to the channel slice
+// (error): Error if the process fails
+func (r *ChannelRepo) GetAll() ([]*models.Channel, error) {
+	rows, err := r.db.Query("SELECT id, orgId, name, type, target, events, hosts, tags, createdAt FROM notification_channels WHERE ($1=0 OR orgId=$1) ORDER BY id", r.orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	channels := make([]*models.Channel, 0)
+	for rows.Next() {
+		channel, err := scanChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// Save: Stores a new notification channel in the database
+// Params:
+// (channel): Reference to the channel object to be stored
+// Return:
+// (int64): Id of the stored channel
+// (error): Error if the process fails
+func (r *ChannelRepo) Save(channel *models.Channel) (int64, error) {
+	id := int64(-1)
+	jsonEvents, jEventsError := json.Marshal(channel.Events)
+	if jEventsError != nil {
+		return id, jEventsError
+	}
+	jsonHosts, jHostsError := json.Marshal(channel.Hosts)
+	if jHostsError != nil {
+		return id, jHostsError
+	}
+	jsonTags, jTagsError := json.Marshal(channel.Tags)
+	if jTagsError != nil {
+		return id, jTagsError
+	}
+	owner, ownerErr := ownerOrg(r.orgID, channel.OrgId)
+	if ownerErr != nil {
+		return id, ownerErr
+	}
+	queryErr := r.db.QueryRow(`INSERT INTO notification_channels (orgId, name, type, target, events, hosts, tags, createdAt) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`, owner, channel.Name, channel.Type, channel.Target, jsonEvents, jsonHosts, jsonTags, channel.CreatedAt).Scan(&id)
+	if queryErr != nil {
+		return id, queryErr
+	}
+	return id, nil
+}
+
+// Delete: Remove a notification channel from the database
+// Params:
+// (ID): Id of the channel you want to remove
+// Return:
+// (error): Error if the process fails
+func (r *ChannelRepo) Delete(ID int64) error {
+	_, err := r.db.Exec("DELETE FROM notification_channels WHERE id=$1 AND ($2=0 OR orgId=$2)", ID, r.orgID)
+	return err
+}
+
+// scanChannel: Auxiliary function that reads a channel from a row
+// Params:
+// (row): Row or rows reference positioned on the record
+// Return:
+// (*models.Channel): Reference to the channel
+// (error): Error if the process fails
+func scanChannel(row interface{ Scan(...interface{}) error }) (*models.Channel, error) {
+	channel := &models.Channel{}
+	var events, hosts, tags []byte
+	if err := row.Scan(&channel.Id, &channel.OrgId, &channel.Name, &channel.Type, &channel.Target, &events, &hosts, &tags, &channel.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(events, &channel.Events); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(hosts, &channel.Hosts); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tags, &channel.Tags); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"database/sql"
+
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// DiscoveryRepo: Structure used to store the database access reference
+type DiscoveryRepo struct {
+	db *sql.DB
+}
+
+// NewDiscoveryRepository: Receives a reference to the database and stores it in the DiscoveryRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*DiscoveryRepo): Reference to the DiscoveryRepo object
+func NewDiscoveryRepository(db *sql.DB) *DiscoveryRepo {
+	return &DiscoveryRepo{db: db}
+}
+
+// FindByDomain: Gets the hosts discovered in the certificates of a domain, sorted by name
+// Params:
+// (domainID): Id of the parent domain
+// Return:
+// ([]*models.DiscoveredHost): Reference to the discovered host slice
+// (error): Error if the process fails
+func (r *DiscoveryRepo) FindByDomain(domainID int64) ([]*models.DiscoveredHost, error) {
+	rows, err := r.db.Query("SELECT id, domainId, name, firstSeenAt, lastSeenAt FROM discovered_hosts WHERE domainId=$1 ORDER BY name", domainID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	hosts := make([]*models.DiscoveredHost, 0)
+	for rows.Next() {
+		host := &models.DiscoveredHost{}
+		if err := rows.Scan(&host.Id, &host.DomainId, &host.Name, &host.FirstSeenAt, &host.LastSeenAt); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+// FindByName: Searchs for a host discovered in the certificates of a domain
+// Params:
+// (domainID): Id of the parent domain
+// (name): Name of the discovered host
+// Return:
+// (*models.DiscoveredHost): Reference to the discovered host that was found
+// (error): Error if the process fails
+func (r *DiscoveryRepo) FindByName(domainID int64, name string) (*models.DiscoveredHost, error) {
+	host := &models.DiscoveredHost{}
+	err := r.db.QueryRow("SELECT id, domainId, name, firstSeenAt, lastSeenAt FROM discovered_hosts WHERE domainId=$1 AND name=$2", domainID, name).Scan(&host.Id, &host.DomainId, &host.Name, &host.FirstSeenAt, &host.LastSeenAt)
+	if err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+// SaveNames: Stores the names found in a scan of a domain. New names are inserted and known names get their last sighting updated
+// Params:
+// (domainID): Id of the parent domain
+// (names): Names found in the scan
+// (seenAt): Unix time of the scan
+// Return:
+// (error): Error if the process fails
+func (r *DiscoveryRepo) SaveNames(domainID int64, names []string, seenAt int64) error {
+	for _, name := range names {
+		_, err := r.db.Exec(`INSERT INTO discovered_hosts (domainId, name, firstSeenAt, lastSeenAt) VALUES ($1, $2, $3, $3)
+			ON CONFLICT (domainId, name) DO UPDATE SET lastSeenAt=excluded.lastSeenAt`, domainID, name, seenAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,24 +1,50 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
 	models "github.com/JonatanOrdonez/tr-backend/models"
+	"github.com/lib/pq"
 )
 
 // DomainRepo: Structure used to store the database access reference
 type DomainRepo struct {
-	db *sql.DB
+	db     *sql.DB
+	orgID  int64
+	ctx    context.Context
+	logger interfaces.ILogger
 }
 
 // NewDomainRepository: Receives a reference to the database and stores it in the DomainRepo structure
 // Params:
 // (db): Reference to the sql.DB database object
+// (logger): Reference to the logger
 // Return:
 // (*DomainRepo): Reference to the DomainRepo object
-func NewDomainRepository(db *sql.DB) *DomainRepo {
-	return &DomainRepo{db: db}
+func NewDomainRepository(db *sql.DB, logger interfaces.ILogger) *DomainRepo {
+	return &DomainRepo{db: db, orgID: unscopedOrg, ctx: context.Background(), logger: logger}
+}
+
+// WithOrg: Returns a copy of the repository whose queries only see the records of an organization.
+// The repository returned by NewDomainRepository is not scoped and is only used by the background jobs
+// Params:
+// (orgID): Id of the organization, an id that is not positive yields a repository that matches no record
+// Return:
+// (interfaces.IDomainRepository): Scoped repository
+func (r *DomainRepo) WithOrg(orgID int64) interfaces.IDomainRepository {
+	return &DomainRepo{db: r.db, orgID: scopeOrg(orgID), ctx: r.ctx, logger: r.logger}
+}
+
+// WithContext: Returns a copy of the repository whose queries are made with a context, so they are traced as its children
+// Params:
+// (ctx): Context of the caller
+// Return:
+// (interfaces.IDomainRepository): Repository bound to the context
+func (r *DomainRepo) WithContext(ctx context.Context) interfaces.IDomainRepository {
+	return &DomainRepo{db: r.db, orgID: r.orgID, ctx: ctx, logger: r.logger}
 }
 
 // FindByID: Searchs for a domain in the database using its id property as a search criteria
@@ -28,59 +54,76 @@ func NewDomainRepository(db *sql.DB) *DomainRepo {
 // (*models.Domain): Reference to the domain that was found
 // (error): Error if the process fails
 func (r *DomainRepo) FindByID(ID int64) (*models.Domain, error) {
-	var id, updatedAt int64
-	var url, sslGrade, previousSslGrade, logo, title string
-	var servers, endpoints []byte
-	var serversChanged, isDown bool
-	err := r.db.QueryRow("SELECT * FROM domains WHERE id=$1", ID).Scan(&id, &servers, &endpoints, &url, &sslGrade, &previousSslGrade, &logo, &title, &updatedAt, &serversChanged, &isDown)
+	row := r.db.QueryRowContext(r.ctx, "SELECT "+domainColumns+" FROM domains WHERE id=$1 AND ($2=0 OR orgId=$2)", ID, r.orgID)
+	return scanDomain(row)
+}
+
+// GetAll: Gets all the records that are in the "domains" table
+// Return:
+// ([]*models.Domain): reference to the domain slice
+// (error): Error if the process fails
+func (r *DomainRepo) GetAll() ([]*models.Domain, error) {
+	rows, err := r.db.QueryContext(r.ctx, "SELECT "+domainColumns+" FROM domains WHERE ($1=0 OR orgId=$1)", r.orgID)
 	if err != nil {
 		return nil, err
 	}
-	var serversStruct []models.Server
-	err = json.Unmarshal(servers, &serversStruct)
-	if err != nil {
+	defer rows.Close()
+	domains := make([]*models.Domain, 0)
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, domain)
+	}
+	if err = rows.Err(); err != nil {
 		return nil, err
 	}
-	var endpointsStruct []models.Endpoint
-	err = json.Unmarshal(endpoints, &endpointsStruct)
+	return domains, nil
+}
+
+// ForEach: Reads the "domains" table with a cursor, sorted by url, and calls a function with each domain
+// without loading the whole table in memory
+// Params:
+// (each): Function called with each domain. The iteration stops if it returns an error
+// Return:
+// (error): Error if the process or the function fails
+func (r *DomainRepo) ForEach(each func(domain *models.Domain) error) error {
+	rows, err := r.db.QueryContext(r.ctx, "SELECT "+domainColumns+" FROM domains WHERE ($1=0 OR orgId=$1) ORDER BY url", r.orgID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	domain := &models.Domain{serversStruct, endpointsStruct, serversChanged, sslGrade, previousSslGrade, logo, title, isDown, id, url, updatedAt}
-	return domain, nil
+	defer rows.Close()
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return err
+		}
+		if err = each(domain); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
 
-// GetAll: Gets all the records that are in the "domains" table
+// FindByTags: Gets the domains that have every one of the given tags, sorted by url
+// Params:
+// (tags): Tags the domains must have
 // Return:
 // ([]*models.Domain): reference to the domain slice
 // (error): Error if the process fails
-func (r *DomainRepo) GetAll() ([]*models.Domain, error) {
-	rows, err := r.db.Query("SELECT * FROM domains")
+func (r *DomainRepo) FindByTags(tags []string) ([]*models.Domain, error) {
+	rows, err := r.db.QueryContext(r.ctx, "SELECT "+domainColumns+" FROM domains WHERE id IN (SELECT domainId FROM domain_tags WHERE tag=ANY($1) GROUP BY domainId HAVING count(*)=$2) AND ($3=0 OR orgId=$3) ORDER BY url", pq.Array(tags), len(tags), r.orgID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	domains := make([]*models.Domain, 0)
 	for rows.Next() {
-		var id, updatedAt int64
-		var url, sslGrade, previousSslGrade, logo, title string
-		var servers, endpoints []byte
-		var serversChanged, isDown bool
-		err := rows.Scan(&id, &servers, &endpoints, &url, &sslGrade, &previousSslGrade, &logo, &title, &updatedAt, &serversChanged, &isDown)
-		if err != nil {
-			return nil, err
-		}
-		var serversStruct []models.Server
-		decodeErr := json.Unmarshal(servers, &serversStruct)
-		if decodeErr != nil {
-			return nil, decodeErr
-		}
-		var endpointsStruct []models.Endpoint
-		decodeErr = json.Unmarshal(endpoints, &endpointsStruct)
+		domain, err := scanDomain(rows)
 		if err != nil {
 			return nil, err
 		}
-		domain := &models.Domain{serversStruct, endpointsStruct, serversChanged, sslGrade, previousSslGrade, logo, title, isDown, id, url, updatedAt}
 		domains = append(domains, domain)
 	}
 	if err = rows.Err(); err != nil {
@@ -105,10 +148,34 @@ func (r *DomainRepo) Save(domain *models.Domain) (int64, error) {
 	if jEndpointsError != nil {
 		return id, jEndpointsError
 	}
-	queryErr := r.db.QueryRow(`INSERT INTO domains (servers, endpoints, url, sslGrade, previousSslGrade, logo, title, updatedAt, serversChanged, isDown) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`, jsonServers, jsonEndpoints, domain.Url, domain.SslGrade, domain.PreviousSslGrade, domain.Logo, domain.Title, domain.UpdatedAt, domain.ServersChanged, domain.IsDown).Scan(&id)
+	jsonCompliance, jComplianceError := json.Marshal(domain.Compliance)
+	if jComplianceError != nil {
+		return id, jComplianceError
+	}
+	jsonDns, jDnsError := json.Marshal(domain.Dns)
+	if jDnsError != nil {
+		return id, jDnsError
+	}
+	jsonHeaders, jHeadersError := json.Marshal(domain.Headers)
+	if jHeadersError != nil {
+		return id, jHeadersError
+	}
+	jsonRegistration, jRegistrationError := json.Marshal(domain.Registration)
+	if jRegistrationError != nil {
+		return id, jRegistrationError
+	}
+	owner, ownerErr := ownerOrg(r.orgID, domain.OrgId)
+	if ownerErr != nil {
+		return id, ownerErr
+	}
+	queryErr := r.db.QueryRowContext(r.ctx, `INSERT INTO domains (orgId, servers, endpoints, url, sslGrade, previousSslGrade, logo, title, updatedAt, serversChanged, isDown, compliance, dns, headersAudit, registration) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id`, owner, jsonServers, jsonEndpoints, domain.Url, domain.SslGrade, domain.PreviousSslGrade, domain.Logo, domain.Title, domain.UpdatedAt, domain.ServersChanged, domain.IsDown, jsonCompliance, jsonDns, jsonHeaders, jsonRegistration).Scan(&id)
 	if queryErr != nil {
 		return id, queryErr
 	}
+	if historyErr := r.saveGradePoint(id, domain); historyErr != nil {
+		return id, historyErr
+	}
+	r.logger.Debug(r.ctx, "Domain stored", "id", id, "url", domain.Url, "sslGrade", domain.SslGrade)
 	return id, nil
 }
 
@@ -128,14 +195,99 @@ func (r *DomainRepo) Update(domain *models.Domain) (int64, error) {
 	if jEndpointsError != nil {
 		return id, jEndpointsError
 	}
-	_, queryErr := r.db.Exec(`UPDATE domains SET servers=$1, endpoints=$2, url=$3, sslGrade=$4, previousSslGrade=$5, logo=$6, title=$7, updatedAt=$8, serversChanged=$9, isDown=$10 WHERE id=$11`, jsonServers, jsonEndpoints, domain.Url, domain.SslGrade, domain.PreviousSslGrade, domain.Logo, domain.Title, domain.UpdatedAt, domain.ServersChanged, domain.IsDown, domain.Id)
+	jsonCompliance, jComplianceError := json.Marshal(domain.Compliance)
+	if jComplianceError != nil {
+		return id, jComplianceError
+	}
+	jsonDns, jDnsError := json.Marshal(domain.Dns)
+	if jDnsError != nil {
+		return id, jDnsError
+	}
+	jsonHeaders, jHeadersError := json.Marshal(domain.Headers)
+	if jHeadersError != nil {
+		return id, jHeadersError
+	}
+	jsonRegistration, jRegistrationError := json.Marshal(domain.Registration)
+	if jRegistrationError != nil {
+		return id, jRegistrationError
+	}
+	result, queryErr := r.db.ExecContext(r.ctx, `UPDATE domains SET servers=$1, endpoints=$2, url=$3, sslGrade=$4, previousSslGrade=$5, logo=$6, title=$7, updatedAt=$8, serversChanged=$9, isDown=$10, compliance=$11, dns=$12, headersAudit=$13, registration=$14 WHERE id=$15 AND ($16=0 OR orgId=$16)`, jsonServers, jsonEndpoints, domain.Url, domain.SslGrade, domain.PreviousSslGrade, domain.Logo, domain.Title, domain.UpdatedAt, domain.ServersChanged, domain.IsDown, jsonCompliance, jsonDns, jsonHeaders, jsonRegistration, domain.Id, r.orgID)
 	if queryErr != nil {
 		return id, queryErr
 	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		r.logger.Warn(r.ctx, "Domain to update not found", "id", domain.Id, "url", domain.Url, "orgId", r.orgID)
+		return id, sql.ErrNoRows
+	}
+	if historyErr := r.saveGradePoint(domain.Id, domain); historyErr != nil {
+		return id, historyErr
+	}
+	r.logger.Debug(r.ctx, "Domain updated", "id", domain.Id, "url", domain.Url, "sslGrade", domain.SslGrade)
 	id = domain.Id
 	return id, nil
 }
 
+// SetDown: Updates only the isDown flag of a domain, so a probe does not write back the rest of a row it read before a scan.
+// The flag is only written when it changes, which makes the transition visible to a single caller
+// Params:
+// (ID): Id of the domain
+// (isDown): New value of the flag
+// Return:
+// (bool): True if the flag changed. False if it already had the value
+// (error): Error if the process fails
+func (r *DomainRepo) SetDown(ID int64, isDown bool) (bool, error) {
+	result, err := r.db.ExecContext(r.ctx, "UPDATE domains SET isDown=$1 WHERE id=$2 AND isDown<>$1 AND ($3=0 OR orgId=$3)", isDown, ID, r.orgID)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
+
+// saveGradePoint: Auxiliary function that records the grade of a scan in the "grade_history" table.
+// A domain stored again without a new scan keeps its updatedAt, so it does not add a point
+// Params:
+// (domainID): Id of the domain
+// (domain): Reference to the stored domain
+// Return:
+// (error): Error if the process fails
+func (r *DomainRepo) saveGradePoint(domainID int64, domain *models.Domain) error {
+	_, err := r.db.ExecContext(r.ctx, "UPSERT INTO grade_history (domainId, scannedAt, sslGrade, isDown) VALUES ($1, $2, $3, $4)", domainID, domain.UpdatedAt, domain.SslGrade, domain.IsDown)
+	return err
+}
+
+// GetGradeHistory: Gets the grades recorded for a domain, the oldest first
+// Params:
+// (domainID): Id of the domain
+// (since): Unix time of the oldest point returned
+// Return:
+// ([]models.GradePoint): Grade point slice
+// (error): Error if the process fails
+func (r *DomainRepo) GetGradeHistory(domainID int64, since int64) ([]models.GradePoint, error) {
+	rows, err := r.db.QueryContext(r.ctx, "SELECT scannedAt, sslGrade, isDown FROM grade_history WHERE domainId=$1 AND scannedAt>=$2 ORDER BY scannedAt", domainID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	points := make([]models.GradePoint, 0)
+	for rows.Next() {
+		var point models.GradePoint
+		var sslGrade string
+		if err := rows.Scan(&point.ScannedAt, &sslGrade, &point.IsDown); err != nil {
+			return nil, err
+		}
+		point.SslGrade = models.Grade(sslGrade)
+		points = append(points, point)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
 // Delete: Remove a record from the domain table
 // Params:
 // (ID): Id of the domain you want to remove
@@ -152,24 +304,61 @@ func (r *DomainRepo) Delete(ID int) error {
 // (*models.Domain): Reference to the domain that was found
 // (error): Error if the process fails
 func (r *DomainRepo) FindByUrl(Url string) (*models.Domain, error) {
-	var id, updatedAt int64
+	row := r.db.QueryRowContext(r.ctx, "SELECT "+domainColumns+" FROM domains WHERE url=$1 AND ($2=0 OR orgId=$2)", Url, r.orgID)
+	return scanDomain(row)
+}
+
+// domainColumns: Columns of the "domains" table read by scanDomain, in order. The tags are read from the "domain_tags" table
+const domainColumns = "id, orgId, servers, endpoints, url, sslGrade, previousSslGrade, logo, title, updatedAt, serversChanged, isDown, compliance, dns, headersAudit, registration, ARRAY(SELECT tag FROM domain_tags WHERE domain_tags.domainId=domains.id ORDER BY tag)"
+
+// scanDomain: Auxiliary function that reads a domain from a row selected with domainColumns
+// Params:
+// (row): Row or rows reference positioned on the record
+// Return:
+// (*models.Domain): Reference to the domain
+// (error): Error if the process fails
+func scanDomain(row interface{ Scan(...interface{}) error }) (*models.Domain, error) {
+	var id, orgID, updatedAt int64
 	var url, sslGrade, previousSslGrade, logo, title string
-	var servers, endpoints []byte
+	var servers, endpoints, compliance, dnsRecords, headersAudit, registration []byte
 	var serversChanged, isDown bool
-	err := r.db.QueryRow("SELECT * FROM domains WHERE url=$1", Url).Scan(&id, &servers, &endpoints, &url, &sslGrade, &previousSslGrade, &logo, &title, &updatedAt, &serversChanged, &isDown)
+	tags := make([]string, 0)
+	err := row.Scan(&id, &orgID, &servers, &endpoints, &url, &sslGrade, &previousSslGrade, &logo, &title, &updatedAt, &serversChanged, &isDown, &compliance, &dnsRecords, &headersAudit, &registration, pq.Array(&tags))
 	if err != nil {
 		return nil, err
 	}
 	var serversStruct []models.Server
-	decodeErr := json.Unmarshal(servers, &serversStruct)
-	if decodeErr != nil {
-		return nil, decodeErr
+	if err = json.Unmarshal(servers, &serversStruct); err != nil {
+		return nil, err
 	}
 	var endpointsStruct []models.Endpoint
-	decodeErr = json.Unmarshal(endpoints, &endpointsStruct)
-	if err != nil {
+	if err = json.Unmarshal(endpoints, &endpointsStruct); err != nil {
 		return nil, err
 	}
-	domain := &models.Domain{serversStruct, endpointsStruct, serversChanged, sslGrade, previousSslGrade, logo, title, isDown, id, url, updatedAt}
+	var complianceStruct *models.Compliance
+	if len(compliance) > 0 {
+		if err = json.Unmarshal(compliance, &complianceStruct); err != nil {
+			return nil, err
+		}
+	}
+	var dnsStruct *models.DnsRecords
+	if len(dnsRecords) > 0 {
+		if err = json.Unmarshal(dnsRecords, &dnsStruct); err != nil {
+			return nil, err
+		}
+	}
+	var headersStruct *models.HeadersAudit
+	if len(headersAudit) > 0 {
+		if err = json.Unmarshal(headersAudit, &headersStruct); err != nil {
+			return nil, err
+		}
+	}
+	var registrationStruct *models.Registration
+	if len(registration) > 0 {
+		if err = json.Unmarshal(registration, &registrationStruct); err != nil {
+			return nil, err
+		}
+	}
+	domain := &models.Domain{Servers: serversStruct, Endpoints: endpointsStruct, ServersChanged: serversChanged, SslGrade: models.Grade(sslGrade), PreviousSslGrade: models.Grade(previousSslGrade), Logo: logo, Title: title, IsDown: isDown, Id: id, OrgId: orgID, Url: url, UpdatedAt: updatedAt, Compliance: complianceStruct, Dns: dnsStruct, Headers: headersStruct, Registration: registrationStruct, Tags: tags}
 	return domain, nil
 }
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// OrgRepo: Structure used to store the database access reference
+type OrgRepo struct {
+	db *sql.DB
+}
+
+// NewOrgRepository: Receives a reference to the database and stores it in the OrgRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*OrgRepo): Reference to the OrgRepo object
+func NewOrgRepository(db *sql.DB) *OrgRepo {
+	return &OrgRepo{db: db}
+}
+
+// FindByID: Searchs for an organization in the database using its id property as a search criteria
+// Params:
+// (ID): Id of the organization you are looking for
+// Return:
+// (*models.Org): Reference to the organization that was found
+// (error): Error if the process fails
+func (r *OrgRepo) FindByID(ID int64) (*models.Org, error) {
+	org := &models.Org{}
+	err := r.db.QueryRow("SELECT id, name, createdAt FROM orgs WHERE id=$1", ID).Scan(&org.Id, &org.Name, &org.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// Save: Stores a new organization in the database
+// Params:
+// (org): Reference to the organization object to be stored
+// Return:
+// (int64): Id of the stored organization
+// (error): Error if the process fails
+func (r *OrgRepo) Save(org *models.Org) (int64, error) {
+	id := int64(-1)
+	queryErr := r.db.QueryRow(`INSERT INTO orgs (name, createdAt) VALUES ($1, $2) RETURNING id`, org.Name, org.CreatedAt).Scan(&id)
+	if queryErr != nil {
+		return id, queryErr
+	}
+	return id, nil
+}
+
+// unscopedOrg: Organization of the repositories returned by the New...Repository constructors, which see the records of
+// every organization. They are only used by the background jobs, the request paths always scope them with WithOrg
+const unscopedOrg int64 = 0
+
+// noOrg: Organization of a repository scoped with an invalid id. No record belongs to it, so its queries match nothing
+// and ownerOrg refuses its inserts
+const noOrg int64 = -1
+
+// scopeOrg: Auxiliary function that validates the organization given to WithOrg. Only positive ids scope a repository,
+// any other value yields noOrg, so a missing or forged organization can never turn into the unscoped value
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (int64): Organization of the scoped repository
+func scopeOrg(orgID int64) int64 {
+	if orgID <= 0 {
+		return noOrg
+	}
+	return orgID
+}
+
+// ownerOrg: Auxiliary function that decides the organization of a new record. A scoped repository
+// always writes its own organization, and an unscoped one keeps the one of the record
+// Params:
+// (orgID): Organization of the repository, unscopedOrg if it is not scoped
+// (recordOrgID): Organization set in the record
+// Return:
+// (int64): Organization to be stored
+// (error): Error if the record would not belong to a valid organization
+func ownerOrg(orgID int64, recordOrgID int64) (int64, error) {
+	if orgID == unscopedOrg {
+		orgID = recordOrgID
+	}
+	if orgID <= 0 {
+		return 0, errors.New("Organization is required")
+	}
+	return orgID, nil
+}
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/JonatanOrdonez/tr-backend/logging"
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// recordingDriver: database/sql driver that answers every query with no rows and records the arguments of each statement
+type recordingDriver struct {
+	mutex sync.Mutex
+	args  [][]driver.Value
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) record(args []driver.Value) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.args = append(d.args, args)
+}
+
+func (d *recordingDriver) take() [][]driver.Value {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	args := d.args
+	d.args = nil
+	return args
+}
+
+type recordingConn struct{ driver *recordingDriver }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{c.driver}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return recordingTx{}, nil }
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+type recordingStmt struct{ driver *recordingDriver }
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.record(args)
+	return driver.RowsAffected(0), nil
+}
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.record(args)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+var testDriver = &recordingDriver{}
+
+func init() {
+	sql.Register("recording", testDriver)
+}
+
+func openRecordingDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("recording", "")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	testDriver.take()
+	return db
+}
+
+func TestScopeOrg(t *testing.T) {
+	cases := []struct {
+		orgID int64
+		want  int64
+	}{
+		{1, 1},
+		{42, 42},
+		{unscopedOrg, noOrg},
+		{noOrg, noOrg},
+		{-9, noOrg},
+	}
+	for _, test := range cases {
+		if got := scopeOrg(test.orgID); got != test.want {
+			t.Errorf("scopeOrg(%d) = %d, want %d", test.orgID, got, test.want)
+		}
+	}
+}
+
+func TestOwnerOrg(t *testing.T) {
+	cases := []struct {
+		name        string
+		orgID       int64
+		recordOrgID int64
+		want        int64
+		wantErr     bool
+	}{
+		{"scoped repository writes its organization", 3, 7, 3, false},
+		{"unscoped repository keeps the organization of the record", unscopedOrg, 7, 7, false},
+		{"unscoped repository needs an organization in the record", unscopedOrg, 0, 0, true},
+		{"repository scoped to no organization cannot write", noOrg, 7, 0, true},
+	}
+	for _, test := range cases {
+		got, err := ownerOrg(test.orgID, test.recordOrgID)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("%s: ownerOrg(%d, %d) = %d, %v, want %d and error %v", test.name, test.orgID, test.recordOrgID, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+// TestWithOrgMatchesNothingForInvalidIds: Scopes every repository with ids that are not positive and checks that
+// the organization sent with its queries is noOrg, which no record has, and never the unscoped value
+func TestWithOrgMatchesNothingForInvalidIds(t *testing.T) {
+	db := openRecordingDB(t)
+	logger, err := logging.New(ioutil.Discard, "text", logging.LevelError)
+	if err != nil {
+		t.Fatalf("logging.New: %v", err)
+	}
+	reads := map[string]func(orgID int64) error{
+		"apiKey": func(orgID int64) error { _, err := NewApiKeyRepository(db).WithOrg(orgID).GetAll(); return err },
+		"audit": func(orgID int64) error {
+			_, err := NewAuditRepository(db).WithOrg(orgID).Find("", 1, 10)
+			return err
+		},
+		"batch": func(orgID int64) error {
+			_, err := NewBatchRepository(db).WithOrg(orgID).FindByID(5)
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		},
+		"channel": func(orgID int64) error { _, err := NewChannelRepository(db).WithOrg(orgID).GetAll(); return err },
+		"domain": func(orgID int64) error {
+			_, err := NewDomainRepository(db, logger).WithOrg(orgID).WithContext(context.Background()).GetAll()
+			return err
+		},
+		"policy":  func(orgID int64) error { _, err := NewPolicyRepository(db).WithOrg(orgID).GetAll(); return err },
+		"stats":   func(orgID int64) error { _, err := NewStatsRepository(db).WithOrg(orgID).CountByGrade(); return err },
+		"tag":     func(orgID int64) error { _, err := NewTagRepository(db).WithOrg(orgID).GetAll(); return err },
+		"webhook": func(orgID int64) error { _, err := NewWebhookRepository(db).WithOrg(orgID).GetAll(); return err },
+	}
+	for name, read := range reads {
+		for _, orgID := range []int64{unscopedOrg, noOrg, -9, 4} {
+			want := scopeOrg(orgID)
+			if err := read(orgID); err != nil {
+				t.Fatalf("%s: read with org %d: %v", name, orgID, err)
+			}
+			statements := testDriver.take()
+			if len(statements) == 0 {
+				t.Fatalf("%s: read with org %d made no query", name, orgID)
+			}
+			for _, args := range statements {
+				found := false
+				for _, arg := range args {
+					if value, ok := arg.(int64); ok && value == want {
+						found = true
+					}
+				}
+				if found == false {
+					t.Errorf("%s: query of a repository scoped with %d has args %v, want org %d", name, orgID, args, want)
+				}
+			}
+		}
+	}
+}
+
+func TestScopedRepositoriesRefuseInsertsWithoutOrg(t *testing.T) {
+	db := openRecordingDB(t)
+	inserts := map[string]func() error{
+		"apiKey": func() error {
+			_, err := NewApiKeyRepository(db).WithOrg(0).Save(&models.ApiKey{OrgId: 3, Name: "key", Scopes: []string{models.ScopeAdmin}})
+			return err
+		},
+		"channel": func() error {
+			_, err := NewChannelRepository(db).WithOrg(-1).Save(&models.Channel{OrgId: 3, Name: "ops"})
+			return err
+		},
+		"webhook": func() error {
+			_, err := NewWebhookRepository(db).WithOrg(0).Save(&models.Webhook{OrgId: 3, Url: "https://example.com"})
+			return err
+		},
+		"policy": func() error {
+			_, err := NewPolicyRepository(db).WithOrg(-2).Save(&models.Policy{OrgId: 3, Name: "grade"})
+			return err
+		},
+		"batch": func() error {
+			_, err := NewBatchRepository(db).WithOrg(0).Create([]string{"example.com"}, 1)
+			return err
+		},
+	}
+	for name, insert := range inserts {
+		if err := insert(); err == nil {
+			t.Errorf("%s: insert through a repository scoped to no organization succeeded", name)
+		}
+		if statements := testDriver.take(); len(statements) != 0 {
+			t.Errorf("%s: insert through a repository scoped to no organization ran %d statements", name, len(statements))
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// PolicyRepo: Structure used to store the database access reference
+type PolicyRepo struct {
+	db    *sql.DB
+	orgID int64
+}
+
+// NewPolicyRepository: Receives a reference to the database and stores it in the PolicyRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*PolicyRepo): Reference to the PolicyRepo object
+func NewPolicyRepository(db *sql.DB) *PolicyRepo {
+	return &PolicyRepo{db: db, orgID: unscopedOrg}
+}
+
+// WithOrg: Returns a copy of the repository whose queries only see the records of an organization.
+// The repository returned by NewPolicyRepository is not scoped and is only used by the background jobs
+// Params:
+// (orgID): Id of the organization, an id that is not positive yields a repository that matches no record
+// Return:
+// (interfaces.IPolicyRepository): Scoped repository
+func (r *PolicyRepo) WithOrg(orgID int64) interfaces.IPolicyRepository {
+	return &PolicyRepo{db: r.db, orgID: scopeOrg(orgID)}
+}
+
+// FindByID: Searchs for a policy in the database using its id property as a search criteria
+// Params:
+// (ID): Id of the policy you are looking for
+// Return:
+// (*models.Policy): Reference to the policy that was found
+// (error): Error if the process fails
+func (r *PolicyRepo) FindByID(ID int64) (*models.Policy, error) {
+	row := r.db.QueryRow("SELECT id, orgId, name, type, minGrade, protocols, days, tags FROM policies WHERE id=$1 AND ($2=0 OR orgId=$2)", ID, r.orgID)
+	return scanPolicy(row)
+}
+
+// GetAll: Gets all the records that are in the "policies" table
+// Return:
+// ([]*models.Policy): reference to the policy slice
+// (error): Error if the process fails
+func (r *PolicyRepo) GetAll() ([]*models.Policy, error) {
+	rows, err := r.db.Query("SELECT id, orgId, name, type, minGrade, protocols, days, tags FROM policies WHERE ($1=0 OR orgId=$1) ORDER BY id", r.orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	policies := make([]*models.Policy, 0)
+	for rows.Next() {
+		policy, err := scanPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// Save: Stores a new policy in the database
+// Params:
+// (policy): Reference to the policy object to be stored
+// Return:
+// (int64): Id of the stored policy
+// (error): Error if the process fails
+func (r *PolicyRepo) Save(policy *models.Policy) (int64, error) {
+	owner, ownerErr := ownerOrg(r.orgID, policy.OrgId)
+	if ownerErr != nil {
+		return -1, ownerErr
+	}
+	return savePolicy(r.db, owner, policy)
+}
+
+// Delete: Remove a policy from the database
+// Params:
+// (ID): Id of the policy you want to remove
+// Return:
+// (error): Error if the process fails
+func (r *PolicyRepo) Delete(ID int64) error {
+	_, err := r.db.Exec("DELETE FROM policies WHERE id=$1 AND ($2=0 OR orgId=$2)", ID, r.orgID)
+	return err
+}
+
+// ReplaceAll: Replaces, in a single transaction, every policy of the organization with the given ones
+// Params:
+// (policies): Policies to be stored
+// Return:
+// (error): Error if the process fails
+func (r *PolicyRepo) ReplaceAll(policies []*models.Policy) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM policies WHERE ($1=0 OR orgId=$1)", r.orgID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, policy := range policies {
+		owner, ownerErr := ownerOrg(r.orgID, policy.OrgId)
+		if ownerErr != nil {
+			tx.Rollback()
+			return ownerErr
+		}
+		id, saveErr := savePolicy(tx, owner, policy)
+		if saveErr != nil {
+			tx.Rollback()
+			return saveErr
+		}
+		policy.Id = id
+	}
+	return tx.Commit()
+}
+
+// savePolicy: Auxiliary function that inserts a policy using a database or a transaction
+// Params:
+// (queryer): Reference to the sql.DB or sql.Tx object
+// (orgID): Id of the organization that owns the policy
+// (policy): Reference to the policy object to be stored
+// Return:
+// (int64): Id of the stored policy
+// (error): Error if the process fails
+func savePolicy(queryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, orgID int64, policy *models.Policy) (int64, error) {
+	id := int64(-1)
+	jsonProtocols, jProtocolsError := json.Marshal(policy.Protocols)
+	if jProtocolsError != nil {
+		return id, jProtocolsError
+	}
+	jsonTags, jTagsError := json.Marshal(policy.Tags)
+	if jTagsError != nil {
+		return id, jTagsError
+	}
+	queryErr := queryer.QueryRow(`INSERT INTO policies (orgId, name, type, minGrade, protocols, days, tags) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, orgID, policy.Name, policy.Type, policy.MinGrade, jsonProtocols, policy.Days, jsonTags).Scan(&id)
+	if queryErr != nil {
+		return id, queryErr
+	}
+	return id, nil
+}
+
+// scanPolicy: Auxiliary function that reads a policy from a row
+// Params:
+// (row): Row or rows reference positioned on the record
+// Return:
+// (*models.Policy): Reference to the policy
+// (error): Error if the process fails
+func scanPolicy(row interface{ Scan(...interface{}) error }) (*models.Policy, error) {
+	policy := &models.Policy{}
+	var minGrade string
+	var protocols, tags []byte
+	if err := row.Scan(&policy.Id, &policy.OrgId, &policy.Name, &policy.Type, &minGrade, &protocols, &policy.Days, &tags); err != nil {
+		return nil, err
+	}
+	policy.MinGrade = models.Grade(minGrade)
+	if err := json.Unmarshal(protocols, &policy.Protocols); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tags, &policy.Tags); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"database/sql"
+
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// ProbeRepo: Structure used to store the database access reference
+type ProbeRepo struct {
+	db *sql.DB
+}
+
+// NewProbeRepository: Receives a reference to the database and stores it in the ProbeRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*ProbeRepo): Reference to the ProbeRepo object
+func NewProbeRepository(db *sql.DB) *ProbeRepo {
+	return &ProbeRepo{db: db}
+}
+
+// Save: Stores a new probe outcome in the database
+// Params:
+// (probe): Reference to the probe object to be stored
+// Return:
+// (int64): Id of the stored probe
+// (error): Error if the process fails
+func (r *ProbeRepo) Save(probe *models.Probe) (int64, error) {
+	id := int64(-1)
+	queryErr := r.db.QueryRow(`INSERT INTO probes (domainId, checkedAt, isUp, statusCode, responseTime, error) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, probe.DomainId, probe.CheckedAt, probe.IsUp, probe.StatusCode, probe.ResponseTime, probe.Error).Scan(&id)
+	if queryErr != nil {
+		return id, queryErr
+	}
+	return id, nil
+}
+
+// FindByDomainSince: Gets the probes of a domain checked after a given moment, ordered by date
+// Params:
+// (domainID): Id of the probed domain
+// (since): Unix time from which the probes are returned
+// Return:
+// ([]*models.Probe): Reference to the probe slice
+// (error): Error if the process fails
+func (r *ProbeRepo) FindByDomainSince(domainID int64, since int64) ([]*models.Probe, error) {
+	rows, err := r.db.Query("SELECT id, domainId, checkedAt, isUp, statusCode, responseTime, error FROM probes WHERE domainId=$1 AND checkedAt>=$2 ORDER BY checkedAt", domainID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	probes := make([]*models.Probe, 0)
+	for rows.Next() {
+		probe := &models.Probe{}
+		err := rows.Scan(&probe.Id, &probe.DomainId, &probe.CheckedAt, &probe.IsUp, &probe.StatusCode, &probe.ResponseTime, &probe.Error)
+		if err != nil {
+			return nil, err
+		}
+		probes = append(probes, probe)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return probes, nil
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// ScheduleRepo: Structure used to store the database access reference
+type ScheduleRepo struct {
+	db *sql.DB
+}
+
+// NewScheduleRepository: Receives a reference to the database and stores it in the ScheduleRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*ScheduleRepo): Reference to the ScheduleRepo object
+func NewScheduleRepository(db *sql.DB) *ScheduleRepo {
+	return &ScheduleRepo{db: db}
+}
+
+// FindByDomain: Searchs for the rescan schedule of a domain
+// Params:
+// (domainID): Id of the domain
+// Return:
+// (*models.Schedule): Reference to the schedule that was found
+// (error): Error if the process fails
+func (r *ScheduleRepo) FindByDomain(domainID int64) (*models.Schedule, error) {
+	schedule := &models.Schedule{}
+	err := r.db.QueryRow("SELECT domainId, scanInterval, nextRunAt FROM scan_schedules WHERE domainId=$1", domainID).Scan(&schedule.DomainId, &schedule.Interval, &schedule.NextRunAt)
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// FindDue: Gets the schedules whose next run is before the given moment, the oldest first
+// Params:
+// (now): Unix time used as a limit
+// (limit): Maximum number of schedules returned
+// Return:
+// ([]*models.Schedule): Reference to the schedule slice
+// (error): Error if the process fails
+func (r *ScheduleRepo) FindDue(now int64, limit int) ([]*models.Schedule, error) {
+	rows, err := r.db.Query("SELECT domainId, scanInterval, nextRunAt FROM scan_schedules WHERE nextRunAt<=$1 ORDER BY nextRunAt LIMIT $2", now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	schedules := make([]*models.Schedule, 0)
+	for rows.Next() {
+		schedule := &models.Schedule{}
+		if err := rows.Scan(&schedule.DomainId, &schedule.Interval, &schedule.NextRunAt); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// tagIntervalExpression: Shortest interval stored for the tags of the domain of the current row, NULL if none of its tags has one
+const tagIntervalExpression = "(SELECT min(tag_schedules.scanInterval) FROM domain_tags JOIN tag_schedules ON tag_schedules.tag=domain_tags.tag AND tag_schedules.orgId=domains.orgId WHERE domain_tags.domainId=domains.id)"
+
+// FindUnscheduled: Gets a new schedule for every domain that does not have one yet, with the shortest interval of its tags,
+// or the default interval if none of its tags has one, and its next run one interval after its last scan
+// Params:
+// (interval): Default interval in seconds
+// Return:
+// ([]*models.Schedule): Reference to the schedule slice, not stored yet
+// (error): Error if the process fails
+func (r *ScheduleRepo) FindUnscheduled(interval int64) ([]*models.Schedule, error) {
+	rows, err := r.db.Query("SELECT id, scanInterval, updatedAt + scanInterval FROM (SELECT domains.id, domains.updatedAt, COALESCE("+tagIntervalExpression+", $1::INT8) AS scanInterval FROM domains LEFT JOIN scan_schedules ON scan_schedules.domainId=domains.id WHERE scan_schedules.domainId IS NULL)", interval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	schedules := make([]*models.Schedule, 0)
+	for rows.Next() {
+		schedule := &models.Schedule{}
+		if err := rows.Scan(&schedule.DomainId, &schedule.Interval, &schedule.NextRunAt); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// FindTagInterval: Gets the shortest interval stored for the tags of a domain
+// Params:
+// (domainID): Id of the domain
+// Return:
+// (int64): Interval in seconds, 0 if none of the tags of the domain has one
+// (error): Error if the process fails
+func (r *ScheduleRepo) FindTagInterval(domainID int64) (int64, error) {
+	var interval sql.NullInt64
+	err := r.db.QueryRow("SELECT "+tagIntervalExpression+" FROM domains WHERE id=$1", domainID).Scan(&interval)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return interval.Int64, nil
+}
+
+// SaveTagInterval: Stores or replaces the rescan interval of a tag of an organization
+// Params:
+// (orgID): Id of the organization
+// (tag): Tag of the domains
+// (interval): Interval in seconds
+// Return:
+// (error): Error if the process fails
+func (r *ScheduleRepo) SaveTagInterval(orgID int64, tag string, interval int64) error {
+	if orgID <= 0 {
+		return errors.New("Organization is required")
+	}
+	_, err := r.db.Exec("UPSERT INTO tag_schedules (orgId, tag, scanInterval) VALUES ($1, $2, $3)", orgID, tag, interval)
+	return err
+}
+
+// Save: Stores or replaces the rescan schedule of a domain
+// Params:
+// (schedule): Reference to the schedule object to be stored
+// Return:
+// (error): Error if the process fails
+func (r *ScheduleRepo) Save(schedule *models.Schedule) error {
+	_, err := r.db.Exec(`UPSERT INTO scan_schedules (domainId, scanInterval, nextRunAt) VALUES ($1, $2, $3)`, schedule.DomainId, schedule.Interval, schedule.NextRunAt)
+	return err
+}
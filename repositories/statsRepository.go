@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"database/sql"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// StatsRepo: Structure used to store the database access reference
+type StatsRepo struct {
+	db    *sql.DB
+	orgID int64
+}
+
+// NewStatsRepository: Receives a reference to the database and stores it in the StatsRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*StatsRepo): Reference to the StatsRepo object
+func NewStatsRepository(db *sql.DB) *StatsRepo {
+	return &StatsRepo{db: db, orgID: unscopedOrg}
+}
+
+// WithOrg: Returns a copy of the repository whose queries only see the records of an organization.
+// The repository returned by NewStatsRepository is not scoped and is only used by the background jobs
+// Params:
+// (orgID): Id of the organization, an id that is not positive yields a repository that matches no record
+// Return:
+// (interfaces.IStatsRepository): Scoped repository
+func (r *StatsRepo) WithOrg(orgID int64) interfaces.IStatsRepository {
+	return &StatsRepo{db: r.db, orgID: scopeOrg(orgID)}
+}
+
+// CountByGrade: Counts the domains by SSL grade, the domains without a grade are counted as not_graded
+// Return:
+// (map[string]int): Number of domains by grade
+// (error): Error if the process fails
+func (r *StatsRepo) CountByGrade() (map[string]int, error) {
+	grades := map[string]int{}
+	rows, err := r.db.Query("SELECT sslGrade, count(*) FROM domains WHERE ($1=0 OR orgId=$1) GROUP BY sslGrade", r.orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var grade string
+		var count int
+		if err := rows.Scan(&grade, &count); err != nil {
+			return nil, err
+		}
+		if grade == "" {
+			grade = "not_graded"
+		}
+		grades[grade] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return grades, nil
+}
+
+// GetStats: Aggregates the "domains" table, and the servers stored in it, in the database
+// Params:
+// (now): Unix time used to compute the age of the scans
+// (changedSince): Unix time of the oldest scan counted in servers_changed
+// (top): Number of owners and countries returned
+// Return:
+// (*models.Stats): Reference to the stats, without the generation time and the window in days
+// (error): Error if the process fails
+func (r *StatsRepo) GetStats(now int64, changedSince int64, top int) (*models.Stats, error) {
+	stats := &models.Stats{}
+	var averageScanAge float64
+	err := r.db.QueryRow(`SELECT count(*),
+		COALESCE(sum(CASE WHEN isDown THEN 1 ELSE 0 END), 0),
+		COALESCE(sum(CASE WHEN serversChanged AND updatedAt>=$2 THEN 1 ELSE 0 END), 0),
+		COALESCE(avg($1 - updatedAt), 0)::FLOAT8
+		FROM domains WHERE ($3=0 OR orgId=$3)`, now, changedSince, r.orgID).Scan(&stats.Domains, &stats.Down, &stats.ServersChanged, &averageScanAge)
+	if err != nil {
+		return nil, err
+	}
+	stats.AverageScanAge = int64(averageScanAge)
+	if stats.GradeDistribution, err = r.CountByGrade(); err != nil {
+		return nil, err
+	}
+	if stats.TopOwners, err = r.topServerField("owner", top); err != nil {
+		return nil, err
+	}
+	if stats.TopCountries, err = r.topServerField("country", top); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// topServerField: Counts the servers of every domain by a field of their JSON, the most common values first
+// Params:
+// (field): JSON field of the server, owner or country
+// (top): Number of values returned
+// Return:
+// ([]models.Count): Count slice
+// (error): Error if the process fails
+func (r *StatsRepo) topServerField(field string, top int) ([]models.Count, error) {
+	rows, err := r.db.Query(`SELECT server->>$1 AS name, count(*) AS total
+		FROM domains, jsonb_array_elements(CASE WHEN jsonb_typeof(servers)='array' THEN servers ELSE '[]'::JSONB END) AS elements(server)
+		WHERE COALESCE(server->>$1, '')<>'' AND ($3=0 OR orgId=$3)
+		GROUP BY name ORDER BY total DESC, name LIMIT $2`, field, top, r.orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make([]models.Count, 0)
+	for rows.Next() {
+		var count models.Count
+		if err := rows.Scan(&count.Name, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"database/sql"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// TagRepo: Structure used to store the database access reference
+type TagRepo struct {
+	db    *sql.DB
+	orgID int64
+}
+
+// NewTagRepository: Receives a reference to the database and stores it in the TagRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*TagRepo): Reference to the TagRepo object
+func NewTagRepository(db *sql.DB) *TagRepo {
+	return &TagRepo{db: db, orgID: unscopedOrg}
+}
+
+// WithOrg: Returns a copy of the repository whose queries only see the records of an organization.
+// The repository returned by NewTagRepository is not scoped and is only used by the background jobs
+// Params:
+// (orgID): Id of the organization, an id that is not positive yields a repository that matches no record
+// Return:
+// (interfaces.ITagRepository): Scoped repository
+func (r *TagRepo) WithOrg(orgID int64) interfaces.ITagRepository {
+	return &TagRepo{db: r.db, orgID: scopeOrg(orgID)}
+}
+
+// GetAll: Gets every tag in use with the number of domains that have it, sorted by tag
+// Return:
+// ([]models.Count): Count slice
+// (error): Error if the process fails
+func (r *TagRepo) GetAll() ([]models.Count, error) {
+	rows, err := r.db.Query("SELECT domain_tags.tag, count(*) FROM domain_tags JOIN domains ON domains.id=domain_tags.domainId WHERE ($1=0 OR domains.orgId=$1) GROUP BY domain_tags.tag ORDER BY domain_tags.tag", r.orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tags := make([]models.Count, 0)
+	for rows.Next() {
+		var tag models.Count
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Add: Attaches a tag to a domain of the organization. Attaching a tag twice has no effect
+// Params:
+// (domainID): Id of the domain
+// (tag): Tag to be attached
+// Return:
+// (error): Error if the process fails
+func (r *TagRepo) Add(domainID int64, tag string) error {
+	_, err := r.db.Exec("UPSERT INTO domain_tags (domainId, tag) SELECT id, $2 FROM domains WHERE id=$1 AND ($3=0 OR orgId=$3)", domainID, tag, r.orgID)
+	return err
+}
+
+// Remove: Detaches a tag from a domain of the organization
+// Params:
+// (domainID): Id of the domain
+// (tag): Tag to be detached
+// Return:
+// (error): Error if the process fails
+func (r *TagRepo) Remove(domainID int64, tag string) error {
+	_, err := r.db.Exec("DELETE FROM domain_tags WHERE domainId IN (SELECT id FROM domains WHERE id=$1 AND ($3=0 OR orgId=$3)) AND tag=$2", domainID, tag, r.orgID)
+	return err
+}
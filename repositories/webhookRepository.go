@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	models "github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// WebhookRepo: Structure used to store the database access reference
+type WebhookRepo struct {
+	db    *sql.DB
+	orgID int64
+}
+
+// NewWebhookRepository: Receives a reference to the database and stores it in the WebhookRepo structure
+// Params:
+// (db): Reference to the sql.DB database object
+// Return:
+// (*WebhookRepo): Reference to the WebhookRepo object
+func NewWebhookRepository(db *sql.DB) *WebhookRepo {
+	return &WebhookRepo{db: db, orgID: unscopedOrg}
+}
+
+// WithOrg: Returns a copy of the repository whose queries only see the records of an organization.
+// The repository returned by NewWebhookRepository is not scoped and is only used by the background jobs
+// Params:
+// (orgID): Id of the organization, an id that is not positive yields a repository that matches no record
+// Return:
+// (interfaces.IWebhookRepository): Scoped repository
+func (r *WebhookRepo) WithOrg(orgID int64) interfaces.IWebhookRepository {
+	return &WebhookRepo{db: r.db, orgID: scopeOrg(orgID)}
+}
+
+// FindByID: Searchs for a webhook in the database using its id property as a search criteria
+// Params:
+// (ID): Id of the webhook you are looking for
+// Return:
+// (*models.Webhook): Reference to the webhook that was found
+// (error): Error if the process fails
+func (r *WebhookRepo) FindByID(ID int64) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	var events []byte
+	err := r.db.QueryRow("SELECT id, orgId, url, secret, events, createdAt FROM webhooks WHERE id=$1 AND ($2=0 OR orgId=$2)", ID, r.orgID).Scan(&webhook.Id, &webhook.OrgId, &webhook.Url, &webhook.Secret, &events, &webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(events, &webhook.Events); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// GetAll: Gets all the records that are in the "webhooks" table
+// Return:
+// ([]*models.Webhook): reference to the webhook slice
+// (error): Error if the process fails
+func (r *WebhookRepo) GetAll() ([]*models.Webhook, error) {
+	rows, err := r.db.Query("SELECT id, orgId, url, secret, events, createdAt FROM webhooks WHERE ($1=0 OR orgId=$1) ORDER BY id", r.orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	webhooks := make([]*models.Webhook, 0)
+	for rows.Next() {
+		webhook := &models.Webhook{}
+		var events []byte
+		if err := rows.Scan(&webhook.Id, &webhook.OrgId, &webhook.Url, &webhook.Secret, &events, &webhook.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(events, &webhook.Events); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// Save: Stores a new webhook in the database
+// Params:
+// (webhook): Reference to the webhook object to be stored
+// Return:
+// (int64): Id of the stored webhook
+// (error): Error if the process fails
+func (r *WebhookRepo) Save(webhook *models.Webhook) (int64, error) {
+	id := int64(-1)
+	jsonEvents, jEventsError := json.Marshal(webhook.Events)
+	if jEventsError != nil {
+		return id, jEventsError
+	}
+	owner, ownerErr := ownerOrg(r.orgID, webhook.OrgId)
+	if ownerErr != nil {
+		return id, ownerErr
+	}
+	queryErr := r.db.QueryRow(`INSERT INTO webhooks (orgId, url, secret, events, createdAt) VALUES ($1, $2, $3, $4, $5) RETURNING id`, owner, webhook.Url, webhook.Secret, jsonEvents, webhook.CreatedAt).Scan(&id)
+	if queryErr != nil {
+		return id, queryErr
+	}
+	return id, nil
+}
+
+// Delete: Remove a webhook and its delivery log from the database
+// Params:
+// (ID): Id of the webhook you want to remove
+// Return:
+// (error): Error if the process fails
+func (r *WebhookRepo) Delete(ID int64) error {
+	if _, err := r.db.Exec("DELETE FROM webhook_deliveries WHERE webhookId IN (SELECT id FROM webhooks WHERE id=$1 AND ($2=0 OR orgId=$2))", ID, r.orgID); err != nil {
+		return err
+	}
+	_, err := r.db.Exec("DELETE FROM webhooks WHERE id=$1 AND ($2=0 OR orgId=$2)", ID, r.orgID)
+	return err
+}
+
+// SaveDelivery: Stores a delivery attempt in the delivery log
+// Params:
+// (delivery): Reference to the delivery object to be stored
+// Return:
+// (int64): Id of the stored delivery
+// (error): Error if the process fails
+func (r *WebhookRepo) SaveDelivery(delivery *models.Delivery) (int64, error) {
+	id := int64(-1)
+	queryErr := r.db.QueryRow(`INSERT INTO webhook_deliveries (webhookId, eventType, attempt, statusCode, success, error, deliveredAt) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, delivery.WebhookId, delivery.EventType, delivery.Attempt, delivery.StatusCode, delivery.Success, delivery.Error, delivery.DeliveredAt).Scan(&id)
+	if queryErr != nil {
+		return id, queryErr
+	}
+	return id, nil
+}
+
+// FindDeliveries: Gets the latest delivery attempts of a webhook, the newest first
+// Params:
+// (webhookID): Id of the webhook
+// (limit): Maximum number of deliveries returned
+// Return:
+// ([]*models.Delivery): Reference to the delivery slice
+// (error): Error if the process fails
+func (r *WebhookRepo) FindDeliveries(webhookID int64, limit int) ([]*models.Delivery, error) {
+	rows, err := r.db.Query("SELECT id, webhookId, eventType, attempt, statusCode, success, error, deliveredAt FROM webhook_deliveries WHERE webhookId IN (SELECT id FROM webhooks WHERE id=$1 AND ($3=0 OR orgId=$3)) ORDER BY deliveredAt DESC, id DESC LIMIT $2", webhookID, limit, r.orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deliveries := make([]*models.Delivery, 0)
+	for rows.Next() {
+		delivery := &models.Delivery{}
+		if err := rows.Scan(&delivery.Id, &delivery.WebhookId, &delivery.EventType, &delivery.Attempt, &delivery.StatusCode, &delivery.Success, &delivery.Error, &delivery.DeliveredAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// apiKeyPrefix: Prefix of every issued key, so they are easy to recognize in logs and secret scanners
+const apiKeyPrefix = "trk_"
+
+// apiKeyTouchInterval: Minimum time, in seconds, between two updates of the last use of a key,
+// so a busy key does not write to the database on every request
+const apiKeyTouchInterval = 60
+
+// ApiKeyService: Structure used to store the apiKeyService functions
+type ApiKeyService struct {
+	apiKeyRepo interfaces.IApiKeyRepository
+	orgService interfaces.IOrgService
+	orgID      int64
+}
+
+// NewApiKeyService: Receives a reference to the apiKeyRepo and orgService interfaces and stores them in the ApiKeyService structure
+// Params:
+// (apiKeyRepo): Reference to an apiKeyRepo interface
+// (orgService): Reference to the orgService interface that checks the organization of the keys
+// Return:
+// (*ApiKeyService): Reference to the ApiKeyService object
+func NewApiKeyService(apiKeyRepo interfaces.IApiKeyRepository, orgService interfaces.IOrgService) *ApiKeyService {
+	return &ApiKeyService{apiKeyRepo: apiKeyRepo, orgService: orgService}
+}
+
+// WithOrg: Returns a copy of the service that only sees, and only issues, the API keys of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IApiKeyService): Scoped service
+func (s *ApiKeyService) WithOrg(orgID int64) interfaces.IApiKeyService {
+	return &ApiKeyService{apiKeyRepo: s.apiKeyRepo.WithOrg(orgID), orgService: s.orgService, orgID: orgID}
+}
+
+// Authenticate: Looks up the key by its hash and returns the principal it identifies, recording its last use
+// Params:
+// (key): Key sent by the client
+// Return:
+// (*models.Principal): Reference to the principal of the key
+// (error): Error if the key is unknown or its organization does not exist
+func (s *ApiKeyService) Authenticate(key string) (*models.Principal, error) {
+	if strings.HasPrefix(key, apiKeyPrefix) == false {
+		return nil, errors.New("Invalid API key")
+	}
+	apiKey, err := s.apiKeyRepo.FindByHash(hashApiKey(key))
+	if err != nil {
+		return nil, errors.New("Invalid API key")
+	}
+	if err = s.orgService.CheckOrg(apiKey.OrgId); err != nil {
+		return nil, errors.New("Invalid API key")
+	}
+	now := time.Now().Unix()
+	if now-apiKey.LastUsedAt >= apiKeyTouchInterval {
+		s.apiKeyRepo.TouchLastUsed(apiKey.Id, now)
+	}
+	return &models.Principal{Subject: fmt.Sprintf("apikey:%d", apiKey.Id), OrgId: apiKey.OrgId, Scopes: apiKey.Scopes}, nil
+}
+
+// IssueKey: Generates a new key for the organization of the service and stores its hash
+// Params:
+// (name): Name of the key
+// (scopes): Scopes granted to the key
+// Return:
+// (*models.ApiKey): Reference to the stored key, the only time its Key field is set
+// (error): Error if the organization does not exist or the process fails
+func (s *ApiKeyService) IssueKey(name string, scopes []string) (*models.ApiKey, error) {
+	if s.orgID <= 0 {
+		return nil, errors.New("Organization is required")
+	}
+	if err := s.orgService.CheckOrg(s.orgID); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("Name is required")
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("At least one scope is required")
+	}
+	for _, scope := range scopes {
+		if isScope(scope) == false {
+			return nil, fmt.Errorf("Unknown scope %s", scope)
+		}
+	}
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+	apiKey := &models.ApiKey{OrgId: s.orgID, Name: strings.TrimSpace(name), Prefix: key[:len(apiKeyPrefix)+8], Hash: hashApiKey(key), Scopes: scopes, CreatedAt: time.Now().Unix()}
+	id, saveErr := s.apiKeyRepo.Save(apiKey)
+	if saveErr != nil {
+		return nil, saveErr
+	}
+	apiKey.Id = id
+	apiKey.Key = key
+	return apiKey, nil
+}
+
+// CreateKey: Issues a new key from a JSON body
+// Params:
+// (body): JSON body with the name and scopes of the key
+// Return:
+// ([]byte): JSON object with the key, which cannot be retrieved again
+// (error): Error if the process fails
+func (s *ApiKeyService) CreateKey(body []byte) ([]byte, error) {
+	var request *models.ApiKey
+	if err := json.Unmarshal(body, &request); err != nil || request == nil {
+		return nil, errors.New("Invalid body")
+	}
+	apiKey, err := s.IssueKey(request.Name, request.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	jsonBody, jsonError := json.Marshal(apiKey)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// GetKeys: Returns a JSON object with the API keys, without the keys themselves
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *ApiKeyService) GetKeys() ([]byte, error) {
+	apiKeys, err := s.apiKeyRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": apiKeys})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// DeleteKey: Revokes an API key
+// Params:
+// (ID): Id of the API key
+// Return:
+// (error): Error if the process fails
+func (s *ApiKeyService) DeleteKey(ID int64) error {
+	if _, err := s.apiKeyRepo.FindByID(ID); err != nil {
+		return errors.New("API key not found")
+	}
+	return s.apiKeyRepo.Delete(ID)
+}
+
+// hashApiKey: Auxiliary function that computes the hash stored for a key
+// Params:
+// (key): Key
+// Return:
+// (string): SHA-256 hash of the key, hex encoded
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// isScope: Auxiliary function that checks if a scope exists
+// Params:
+// (scope): Scope to be checked
+// Return:
+// (bool): True if the scope exists. False if not
+func isScope(scope string) bool {
+	for _, known := range models.Scopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// fakeApiKeyRepository: In memory IApiKeyRepository that records the keys touched and saved
+type fakeApiKeyRepository struct {
+	keys    []*models.ApiKey
+	touched []int64
+	saved   []*models.ApiKey
+}
+
+func (r *fakeApiKeyRepository) WithOrg(orgID int64) interfaces.IApiKeyRepository { return r }
+
+func (r *fakeApiKeyRepository) FindByID(ID int64) (*models.ApiKey, error) {
+	for _, apiKey := range r.keys {
+		if apiKey.Id == ID {
+			return apiKey, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeApiKeyRepository) FindByHash(hash string) (*models.ApiKey, error) {
+	for _, apiKey := range r.keys {
+		if apiKey.Hash == hash {
+			return apiKey, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeApiKeyRepository) GetAll() ([]*models.ApiKey, error) { return r.keys, nil }
+
+func (r *fakeApiKeyRepository) Save(apiKey *models.ApiKey) (int64, error) {
+	r.saved = append(r.saved, apiKey)
+	return int64(len(r.saved)), nil
+}
+
+func (r *fakeApiKeyRepository) Delete(ID int64) error { return nil }
+
+func (r *fakeApiKeyRepository) TouchLastUsed(ID int64, lastUsedAt int64) error {
+	r.touched = append(r.touched, ID)
+	return nil
+}
+
+// fakeOrgService: IOrgService that only knows the organizations of its map
+type fakeOrgService struct {
+	orgs map[int64]bool
+}
+
+func (s *fakeOrgService) GetOrg(ID int64) ([]byte, error) { return nil, errors.New("Not implemented") }
+
+func (s *fakeOrgService) CreateOrg(name string) (*models.Org, error) {
+	return nil, errors.New("Not implemented")
+}
+
+func (s *fakeOrgService) CheckOrg(ID int64) error {
+	if s.orgs[ID] == false {
+		return errors.New("Unknown organization")
+	}
+	return nil
+}
+
+func TestApiKeyServiceAuthenticate(t *testing.T) {
+	const (
+		activeKey   = apiKeyPrefix + "active"
+		idleKey     = apiKeyPrefix + "idle"
+		orphanKey   = apiKeyPrefix + "orphan"
+		unknownKey  = apiKeyPrefix + "unknown"
+		unprefixKey = "active"
+	)
+	now := time.Now().Unix()
+	repo := &fakeApiKeyRepository{keys: []*models.ApiKey{
+		{Id: 1, OrgId: 3, Hash: hashApiKey(activeKey), Scopes: []string{models.ScopeDomainsRead}, LastUsedAt: now},
+		{Id: 2, OrgId: 3, Hash: hashApiKey(idleKey), Scopes: []string{models.ScopeAdmin}, LastUsedAt: now - 2*apiKeyTouchInterval},
+		{Id: 3, OrgId: 9, Hash: hashApiKey(orphanKey), Scopes: []string{models.ScopeAdmin}},
+		{Id: 4, OrgId: 3, Hash: hashApiKey(unprefixKey), Scopes: []string{models.ScopeAdmin}},
+	}}
+	service := NewApiKeyService(repo, &fakeOrgService{orgs: map[int64]bool{3: true}})
+
+	cases := []struct {
+		name    string
+		key     string
+		want    *models.Principal
+		touched []int64
+	}{
+		{"recently used key", activeKey, &models.Principal{Subject: "apikey:1", OrgId: 3, Scopes: []string{models.ScopeDomainsRead}}, nil},
+		{"idle key records its use", idleKey, &models.Principal{Subject: "apikey:2", OrgId: 3, Scopes: []string{models.ScopeAdmin}}, []int64{2}},
+		{"key of an unknown organization", orphanKey, nil, nil},
+		{"unknown key", unknownKey, nil, nil},
+		{"key without the prefix", unprefixKey, nil, nil},
+		{"empty key", "", nil, nil},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			repo.touched = nil
+			principal, err := service.Authenticate(test.key)
+			if test.want == nil {
+				if err == nil || err.Error() != "Invalid API key" {
+					t.Errorf("Authenticate = %+v, %v, want the error Invalid API key", principal, err)
+				}
+			} else if err != nil || reflect.DeepEqual(principal, test.want) == false {
+				t.Errorf("Authenticate = %+v, %v, want %+v", principal, err, test.want)
+			}
+			if reflect.DeepEqual(repo.touched, test.touched) == false {
+				t.Errorf("touched keys = %v, want %v", repo.touched, test.touched)
+			}
+		})
+	}
+}
+
+func TestApiKeyServiceIssueKey(t *testing.T) {
+	repo := &fakeApiKeyRepository{}
+	service := NewApiKeyService(repo, &fakeOrgService{orgs: map[int64]bool{3: true}})
+	for _, orgID := range []int64{0, -1, 9} {
+		if _, err := service.WithOrg(orgID).(*ApiKeyService).IssueKey("ci", []string{models.ScopeDomainsRead}); err == nil {
+			t.Errorf("IssueKey for organization %d succeeded", orgID)
+		}
+	}
+	if len(repo.saved) != 0 {
+		t.Fatalf("%d keys saved for invalid organizations", len(repo.saved))
+	}
+
+	apiKey, err := service.WithOrg(3).(*ApiKeyService).IssueKey(" ci ", []string{models.ScopeDomainsRead})
+	if err != nil {
+		t.Fatalf("IssueKey: %v", err)
+	}
+	if apiKey.OrgId != 3 || apiKey.Name != "ci" || apiKey.Hash != hashApiKey(apiKey.Key) || apiKey.Prefix != apiKey.Key[:len(apiKeyPrefix)+8] {
+		t.Errorf("issued key = %+v", apiKey)
+	}
+	repo.keys = repo.saved
+	principal, err := service.Authenticate(apiKey.Key)
+	if err != nil || principal.OrgId != 3 {
+		t.Errorf("Authenticate(issued key) = %+v, %v", principal, err)
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// auditLimit: Maximum number of events returned by a query of the audit log
+const auditLimit = 1000
+
+// AuditService: Structure used to store the auditService functions
+type AuditService struct {
+	auditRepo interfaces.IAuditRepository
+	retention time.Duration
+	tick      time.Duration
+	logger    interfaces.ILogger
+}
+
+// NewAuditService: Receives a reference to the auditRepo interface and the retention of the events and stores them in the AuditService structure
+// Params:
+// (auditRepo): Reference to an auditRepo interface
+// (retention): Age after which the events are removed by the retention job
+// (logger): Reference to the logger
+// Return:
+// (*AuditService): Reference to the AuditService object
+func NewAuditService(auditRepo interfaces.IAuditRepository, retention time.Duration, logger interfaces.ILogger) *AuditService {
+	return &AuditService{auditRepo: auditRepo, retention: retention, tick: time.Hour, logger: logger}
+}
+
+// WithOrg: Returns a copy of the service that only sees, and only records, the events of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IAuditService): Scoped service
+func (s *AuditService) WithOrg(orgID int64) interfaces.IAuditService {
+	scoped := *s
+	scoped.auditRepo = s.auditRepo.WithOrg(orgID)
+	return &scoped
+}
+
+// Start: Launches the retention job that removes, every hour, the events older than the retention
+func (s *AuditService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.tick)
+		defer ticker.Stop()
+		for {
+			if removed, err := s.Prune(); err != nil {
+				s.logger.Error(context.Background(), "Audit retention failed", "error", err)
+			} else {
+				s.logger.Debug(context.Background(), "Audit retention done", "removed", removed)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// Prune: Removes the events older than the retention
+// Return:
+// (int64): Number of removed events
+// (error): Error if the process fails
+func (s *AuditService) Prune() (int64, error) {
+	return s.auditRepo.DeleteBefore(time.Now().Add(-s.retention).Unix())
+}
+
+// Record: Appends an event to the audit log
+// Params:
+// (event): Reference to the event
+// Return:
+// (error): Error if the process fails
+func (s *AuditService) Record(event *models.AuditEvent) error {
+	if event.OccurredAt == 0 {
+		event.OccurredAt = time.Now().Unix()
+	}
+	id, err := s.auditRepo.Save(event)
+	if err != nil {
+		return err
+	}
+	event.Id = id
+	return nil
+}
+
+// GetEvents: Returns a JSON object with the latest events of the audit log, the newest first
+// Params:
+// (actor): Actor of the events, every actor if empty
+// (since): Oldest moment, as unix time, RFC 3339 time or date. Every kept event if empty
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *AuditService) GetEvents(actor string, since string) ([]byte, error) {
+	sinceTime, err := parseSince(since)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.auditRepo.Find(actor, sinceTime, auditLimit)
+	if err != nil {
+		return nil, err
+	}
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": events})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// parseSince: Auxiliary function that reads a moment given as unix time, RFC 3339 time or date
+// Params:
+// (since): Moment, empty for the beginning of time
+// Return:
+// (int64): Unix time
+// (error): Error if the format is invalid
+func parseSince(since string) (int64, error) {
+	if since == "" {
+		return 0, nil
+	}
+	if unix, err := strconv.ParseInt(since, 10, 64); err == nil {
+		return unix, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if parsed, err := time.Parse(layout, since); err == nil {
+			return parsed.Unix(), nil
+		}
+	}
+	return 0, errors.New("Invalid since")
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// maxBatchHosts: Maximum number of hosts accepted in a single batch
+const maxBatchHosts = 1000
+
+// hostnameRegex: Regex used to validate the hosts of a batch
+var hostnameRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// BatchService: Structure used to store the batchService functions
+type BatchService struct {
+	batchRepo     interfaces.IBatchRepository
+	domainService interfaces.IDomainService
+	throttle      interfaces.IScanThrottle
+	tick          time.Duration
+	logger        interfaces.ILogger
+}
+
+// NewBatchService: Receives a reference to the batchRepo, domainService, throttle and logger interfaces and stores them in the BatchService structure
+// Params:
+// (batchRepo): Reference to a batchRepo interface
+// (domainService): Reference to a domainService interface, used to analyze the hosts
+// (throttle): Reference to the throttle shared by the background scans
+// (logger): Reference to the logger of the failures of the worker
+// Return:
+// (*BatchService): Reference to the BatchService object
+func NewBatchService(batchRepo interfaces.IBatchRepository, domainService interfaces.IDomainService, throttle interfaces.IScanThrottle, logger interfaces.ILogger) *BatchService {
+	return &BatchService{batchRepo: batchRepo, domainService: domainService, throttle: throttle, tick: 5 * time.Second, logger: logger}
+}
+
+// WithOrg: Returns a copy of the service that only sees, and only creates, the batches of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IBatchService): Scoped service
+func (s *BatchService) WithOrg(orgID int64) interfaces.IBatchService {
+	scoped := *s
+	scoped.batchRepo = s.batchRepo.WithOrg(orgID)
+	scoped.domainService = s.domainService.WithOrg(orgID)
+	return &scoped
+}
+
+// Start: Launches the background worker that analyzes the queued hosts one by one.
+// The hosts left running by a previous process are queued again
+func (s *BatchService) Start() {
+	if err := s.batchRepo.RequeueRunning(); err != nil {
+		s.logger.Error(context.Background(), "Running batch hosts cannot be queued again", "error", err)
+	}
+	go func() {
+		for {
+			processed, err := s.ProcessQueue()
+			if err != nil {
+				s.logger.Error(context.Background(), "Batch queue cannot be processed", "error", err)
+			}
+			if err != nil || processed == 0 {
+				time.Sleep(s.tick)
+			}
+		}
+	}()
+}
+
+// ProcessQueue: Analyzes the next queued hosts, respecting the throttle between two scans
+// Return:
+// (int): Number of hosts analyzed
+// (error): Error if the process fails
+func (s *BatchService) ProcessQueue() (int, error) {
+	hosts, err := s.batchRepo.FindQueued(10)
+	if err != nil {
+		return 0, err
+	}
+	for _, host := range hosts {
+		s.throttle.Wait()
+		host.Status, host.UpdatedAt = models.BatchRunning, time.Now().Unix()
+		if err := s.batchRepo.UpdateHost(host); err != nil {
+			return 0, err
+		}
+		host.Status, host.Error = models.BatchDone, ""
+		if _, checkErr := s.domainService.WithOrg(host.OrgId).CheckDomain(host.Host); checkErr != nil {
+			host.Status, host.Error = models.BatchFailed, checkErr.Error()
+		}
+		host.UpdatedAt = time.Now().Unix()
+		if err := s.batchRepo.UpdateHost(host); err != nil {
+			return 0, err
+		}
+	}
+	return len(hosts), nil
+}
+
+// CreateBatch: Validates and deduplicates a list of hosts and queues them for analysis
+// Params:
+// (content): List of hosts, as a JSON array, a CSV file whose first column is the host, or one host per line
+// (format): Format of the content, models.BulkJSON, models.BulkCSV or models.BulkText
+// Return:
+// ([]byte): JSON object with the batch and the rejected hosts
+// (error): Error if the process fails
+func (s *BatchService) CreateBatch(content []byte, format string) ([]byte, error) {
+	entries, err := parseBulkHosts(content, format)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(entries))
+	rejected := make([]models.RejectedHost, 0)
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		host := strings.ToLower(hostname(strings.TrimSpace(entry)))
+		if host == "" {
+			continue
+		}
+		if len(host) > 253 || hostnameRegex.MatchString(host) == false {
+			rejected = append(rejected, models.RejectedHost{Host: entry, Reason: "Invalid host"})
+			continue
+		}
+		if seen[host] {
+			rejected = append(rejected, models.RejectedHost{Host: entry, Reason: "Duplicated host"})
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("No valid hosts")
+	}
+	if len(hosts) > maxBatchHosts {
+		return nil, fmt.Errorf("A batch accepts up to %d hosts", maxBatchHosts)
+	}
+	id, err := s.batchRepo.Create(hosts, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	batch, err := s.batchRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	summarizeBatch(batch)
+	batch.Rejected = rejected
+	jsonBody, jsonError := json.Marshal(batch)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// GetBatch: Returns a JSON object with the progress of a batch
+// Params:
+// (ID): Id of the batch
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *BatchService) GetBatch(ID int64) ([]byte, error) {
+	batch, err := s.batchRepo.FindByID(ID)
+	if err != nil {
+		return nil, errors.New("Batch not found")
+	}
+	summarizeBatch(batch)
+	jsonBody, jsonError := json.Marshal(batch)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// summarizeBatch: Auxiliary function that counts the hosts of a batch by status and computes the batch status
+// Params:
+// (batch): Reference to the batch
+func summarizeBatch(batch *models.Batch) {
+	batch.Total = len(batch.Hosts)
+	for _, host := range batch.Hosts {
+		switch host.Status {
+		case models.BatchQueued:
+			batch.Queued++
+		case models.BatchRunning:
+			batch.Running++
+		case models.BatchDone:
+			batch.Done++
+		case models.BatchFailed:
+			batch.Failed++
+		}
+	}
+	switch {
+	case batch.Queued == batch.Total:
+		batch.Status = models.BatchQueued
+	case batch.Done+batch.Failed == batch.Total:
+		batch.Status = models.BatchDone
+	default:
+		batch.Status = models.BatchRunning
+	}
+}
+
+// parseBulkHosts: Auxiliary function that reads the hosts of a bulk request
+// Params:
+// (content): Body or uploaded file
+// (format): Format of the content
+// Return:
+// ([]string): Hosts as they were written
+// (error): Error if the content cannot be read
+func parseBulkHosts(content []byte, format string) ([]string, error) {
+	entries := make([]string, 0)
+	switch format {
+	case models.BulkJSON:
+		if err := json.Unmarshal(content, &entries); err != nil {
+			return nil, errors.New("Body must be a JSON array of hosts")
+		}
+	case models.BulkCSV:
+		reader := csv.NewReader(bytes.NewReader(content))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		for row := 0; ; row++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, errors.New("Invalid CSV file")
+			}
+			if len(record) == 0 || (row == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "host")) {
+				continue
+			}
+			entries = append(entries, record[0])
+		}
+	default:
+		for _, line := range strings.Split(string(content), "\n") {
+			entries = append(entries, strings.TrimSpace(line))
+		}
+	}
+	return entries, nil
+}
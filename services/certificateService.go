@@ -0,0 +1,101 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// CertificateService: Structure used to store the certificateService functions
+type CertificateService struct {
+	domainRepo interfaces.IDomainRepository
+}
+
+// NewCertificateService: Receives a reference to the domainRepo interface and stores it in the CertificateService structure
+// Params:
+// (domainRepo): Reference to a domainRepo interface
+// Return:
+// (*CertificateService): Reference to the CertificateService object
+func NewCertificateService(domainRepo interfaces.IDomainRepository) *CertificateService {
+	return &CertificateService{domainRepo: domainRepo}
+}
+
+// WithOrg: Returns a copy of the service that only sees the domains of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.ICertificateService): Scoped service
+func (s *CertificateService) WithOrg(orgID int64) interfaces.ICertificateService {
+	return &CertificateService{domainRepo: s.domainRepo.WithOrg(orgID)}
+}
+
+// GetExpiring: Returns a JSON object with the leaf certificates of the tracked domains that expire within the given days,
+// the closest expiry first. Certificates that already expired are included
+// Params:
+// (days): Size of the window in days
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *CertificateService) GetExpiring(days int) ([]byte, error) {
+	if days <= 0 {
+		return nil, errors.New("Invalid days")
+	}
+	domains, err := s.domainRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	limit := now.Add(time.Duration(days) * 24 * time.Hour)
+	expiring := make([]*models.ExpiringCertificate, 0)
+	for _, domain := range domains {
+		for _, endpoint := range domain.Endpoints {
+			certificate := toCertificate(endpoint.Certificate)
+			if certificate == nil {
+				continue
+			}
+			notAfter := time.Unix(certificate.NotAfter, 0)
+			if notAfter.After(limit) {
+				continue
+			}
+			daysLeft := int(notAfter.Sub(now).Hours() / 24)
+			expiring = append(expiring, &models.ExpiringCertificate{Url: domain.Url, Address: endpoint.IpAddress, DaysLeft: daysLeft, Certificate: certificate})
+		}
+	}
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiring[i].Certificate.NotAfter < expiring[j].Certificate.NotAfter
+	})
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": expiring})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// toCertificate: Auxiliary function that converts an SSL Labs certificate into the certificate exposed by the API.
+// SSL Labs dates are in milliseconds and are converted to unix seconds
+// Params:
+// (cert): Reference to the SSL Labs certificate
+// Return:
+// (*models.Certificate): Reference to the certificate, nil if there is no certificate
+func toCertificate(cert *models.Cert) *models.Certificate {
+	if cert == nil {
+		return nil
+	}
+	sans := cert.AltNames
+	if sans == nil {
+		sans = []string{}
+	}
+	return &models.Certificate{
+		Subject:   cert.Subject,
+		Sans:      sans,
+		Issuer:    cert.IssuerSubject,
+		NotBefore: cert.NotBefore / 1000,
+		NotAfter:  cert.NotAfter / 1000,
+		Serial:    cert.SerialNumber,
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// endpointFields: Endpoint fields that can take part in the change detection, by their SSL Labs name
+var endpointFields = map[string]func(endpoint models.Endpoint) string{
+	"serverName":           func(e models.Endpoint) string { return e.ServerName },
+	"statusMessage":        func(e models.Endpoint) string { return e.StatusMessage },
+	"grade":                func(e models.Endpoint) string { return string(e.Grade) },
+	"gradeTrustIgnored":    func(e models.Endpoint) string { return string(e.GradeTrustIgnored) },
+	"hasWarnings":          func(e models.Endpoint) string { return fmt.Sprint(e.HasWarnings) },
+	"isExceptional":        func(e models.Endpoint) string { return fmt.Sprint(e.IsExceptional) },
+	"progress":             func(e models.Endpoint) string { return fmt.Sprint(e.Progress) },
+	"duration":             func(e models.Endpoint) string { return fmt.Sprint(e.Duration) },
+	"statusDetails":        func(e models.Endpoint) string { return e.StatusDetails },
+	"statusDetailsMessage": func(e models.Endpoint) string { return e.StatusDetailsMessage },
+	"delegation":           func(e models.Endpoint) string { return fmt.Sprint(e.Delegation) },
+}
+
+// DefaultChangeFields: Endpoint fields compared by default. Progress, duration and the status
+// messages describe the assessment itself, not the infrastructure, so they are left out
+var DefaultChangeFields = []string{"serverName", "grade", "gradeTrustIgnored", "hasWarnings", "isExceptional", "delegation"}
+
+// ChangeDetector: Structure used to store the change detection policy.
+// The policy has two rules:
+// 1. The endpoints of two scans differ when the set of ip addresses differs, or when an endpoint
+// with the same ip address differs in one of the configured fields.
+// 2. The servers of a domain are fetched again when the endpoints differ, or when the last update
+// is older than the refresh window. servers_changed is true only when the fetched servers
+// (address, grade, country and owner) differ from the stored ones.
+type ChangeDetector struct {
+	fields        []string
+	refreshWindow time.Duration
+}
+
+// NewChangeDetector: Receives the policy configuration and stores it in the ChangeDetector structure
+// Params:
+// (fields): Endpoint fields that count as a change, DefaultChangeFields if empty
+// (refreshWindow): Maximum age of the servers before they are fetched again
+// Return:
+// (*ChangeDetector): Reference to the ChangeDetector object
+// (error): Error if a field is unknown
+func NewChangeDetector(fields []string, refreshWindow time.Duration) (*ChangeDetector, error) {
+	if len(fields) == 0 {
+		fields = DefaultChangeFields
+	}
+	cleanFields := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if _, ok := endpointFields[field]; ok == false {
+			return nil, fmt.Errorf("Unknown endpoint field %s", field)
+		}
+		cleanFields = append(cleanFields, field)
+	}
+	return &ChangeDetector{fields: cleanFields, refreshWindow: refreshWindow}, nil
+}
+
+// EndpointsChanged: Compares the endpoints of two scans using the configured fields
+// Params:
+// (previous): Endpoints of the stored scan
+// (current): Endpoints of the new scan
+// Return:
+// (bool): True if the endpoints differ. False if not
+func (d *ChangeDetector) EndpointsChanged(previous []models.Endpoint, current []models.Endpoint) bool {
+	if len(previous) != len(current) {
+		return true
+	}
+	for _, previousEndpoint := range previous {
+		currentEndpoint, err := findEndpoint(previousEndpoint.IpAddress, current)
+		if err != nil {
+			return true
+		}
+		for _, field := range d.fields {
+			value := endpointFields[field]
+			if value(previousEndpoint) != value(currentEndpoint) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NeedsRefresh: Decides if the servers of a domain must be fetched again
+// Params:
+// (updatedAt): Unix time of the last update of the domain
+// (endpointsChanged): Result of EndpointsChanged for the new scan
+// (now): Current time
+// Return:
+// (bool): True if the servers must be fetched. False if not
+func (d *ChangeDetector) NeedsRefresh(updatedAt int64, endpointsChanged bool, now time.Time) bool {
+	return endpointsChanged || now.Sub(time.Unix(updatedAt, 0)) >= d.refreshWindow
+}
+
+// ServersChanged: Compares the stored servers of a domain with the fetched ones, regardless of their order
+// Params:
+// (previous): Stored servers
+// (current): Fetched servers
+// Return:
+// (bool): True if the servers differ. False if not
+func (d *ChangeDetector) ServersChanged(previous []models.Server, current []models.Server) bool {
+	if len(previous) != len(current) {
+		return true
+	}
+	previousKeys, currentKeys := serverKeys(previous), serverKeys(current)
+	for ii := range previousKeys {
+		if previousKeys[ii] != currentKeys[ii] {
+			return true
+		}
+	}
+	return false
+}
+
+// serverKeys: Auxiliary function that converts a server slice into a sorted slice of comparable keys
+// Params:
+// (servers): Server slice
+// Return:
+// ([]string): Sorted keys
+func serverKeys(servers []models.Server) []string {
+	keys := make([]string, 0, len(servers))
+	for _, server := range servers {
+		keys = append(keys, strings.Join([]string{server.Address, string(server.SslGrade), server.Country, server.Owner}, "|"))
+	}
+	sort.Strings(keys)
+	return keys
+}
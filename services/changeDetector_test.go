@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+func newTestDetector(t *testing.T, fields []string, refreshWindow time.Duration) *ChangeDetector {
+	detector, err := NewChangeDetector(fields, refreshWindow)
+	if err != nil {
+		t.Fatalf("NewChangeDetector(%v): %v", fields, err)
+	}
+	return detector
+}
+
+func TestNewChangeDetectorRejectsUnknownFields(t *testing.T) {
+	if _, err := NewChangeDetector([]string{"grade", "color"}, time.Hour); err == nil {
+		t.Fatal("NewChangeDetector accepted an unknown field")
+	}
+}
+
+func TestEndpointsChanged(t *testing.T) {
+	base := []models.Endpoint{
+		{IpAddress: "10.0.0.1", ServerName: "a.example.com", Grade: models.GradeA, StatusMessage: "Ready", Progress: 100, Duration: 5000},
+		{IpAddress: "10.0.0.2", ServerName: "b.example.com", Grade: models.GradeB, StatusMessage: "Ready", Progress: 100, Duration: 6000},
+	}
+	modify := func(change func(endpoints []models.Endpoint) []models.Endpoint) []models.Endpoint {
+		endpoints := append([]models.Endpoint{}, base...)
+		return change(endpoints)
+	}
+	cases := []struct {
+		name    string
+		fields  []string
+		current []models.Endpoint
+		want    bool
+	}{
+		{"same endpoints", nil, modify(func(e []models.Endpoint) []models.Endpoint { return e }), false},
+		{"reordered endpoints", nil, modify(func(e []models.Endpoint) []models.Endpoint { e[0], e[1] = e[1], e[0]; return e }), false},
+		{"progress ignored", nil, modify(func(e []models.Endpoint) []models.Endpoint { e[0].Progress = 40; return e }), false},
+		{"duration ignored", nil, modify(func(e []models.Endpoint) []models.Endpoint { e[1].Duration = 1; return e }), false},
+		{"status message ignored", nil, modify(func(e []models.Endpoint) []models.Endpoint { e[0].StatusMessage = "In progress"; return e }), false},
+		{"grade counted", nil, modify(func(e []models.Endpoint) []models.Endpoint { e[0].Grade = models.GradeC; return e }), true},
+		{"server name counted", nil, modify(func(e []models.Endpoint) []models.Endpoint { e[1].ServerName = "c.example.com"; return e }), true},
+		{"new ip address", nil, modify(func(e []models.Endpoint) []models.Endpoint { e[1].IpAddress = "10.0.0.3"; return e }), true},
+		{"endpoint removed", nil, modify(func(e []models.Endpoint) []models.Endpoint { return e[:1] }), true},
+		{"configured progress counted", []string{"progress"}, modify(func(e []models.Endpoint) []models.Endpoint { e[0].Progress = 40; return e }), true},
+		{"configured status message counted", []string{"statusMessage"}, modify(func(e []models.Endpoint) []models.Endpoint { e[0].StatusMessage = "In progress"; return e }), true},
+		{"unconfigured grade ignored", []string{"serverName"}, modify(func(e []models.Endpoint) []models.Endpoint { e[0].Grade = models.GradeC; return e }), false},
+	}
+	for _, c := range cases {
+		detector := newTestDetector(t, c.fields, time.Hour)
+		if got := detector.EndpointsChanged(base, c.current); got != c.want {
+			t.Errorf("%s: EndpointsChanged = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestNeedsRefresh(t *testing.T) {
+	window := 24 * time.Hour
+	detector := newTestDetector(t, nil, window)
+	now := time.Unix(1600000000, 0)
+	cases := []struct {
+		name             string
+		updatedAt        time.Time
+		endpointsChanged bool
+		want             bool
+	}{
+		{"before the window", now.Add(-window + time.Second), false, false},
+		{"at the window", now.Add(-window), false, true},
+		{"after the window", now.Add(-window - time.Second), false, true},
+		{"endpoints changed before the window", now.Add(-time.Minute), true, true},
+	}
+	for _, c := range cases {
+		if got := detector.NeedsRefresh(c.updatedAt.Unix(), c.endpointsChanged, now); got != c.want {
+			t.Errorf("%s: NeedsRefresh = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestServersChanged(t *testing.T) {
+	detector := newTestDetector(t, nil, time.Hour)
+	previous := []models.Server{
+		{Address: "10.0.0.1", SslGrade: models.GradeA, Country: "US", Owner: "Example"},
+		{Address: "10.0.0.2", SslGrade: models.GradeB, Country: "CO", Owner: "Other"},
+	}
+	cases := []struct {
+		name    string
+		current []models.Server
+		want    bool
+	}{
+		{"reordered servers", []models.Server{previous[1], previous[0]}, false},
+		{"same servers", []models.Server{previous[0], previous[1]}, false},
+		{"grade changed", []models.Server{previous[0], {Address: "10.0.0.2", SslGrade: models.GradeC, Country: "CO", Owner: "Other"}}, true},
+		{"owner changed", []models.Server{{Address: "10.0.0.1", SslGrade: models.GradeA, Country: "US", Owner: "New"}, previous[1]}, true},
+		{"server removed", []models.Server{previous[1]}, true},
+	}
+	for _, c := range cases {
+		if got := detector.ServersChanged(previous, c.current); got != c.want {
+			t.Errorf("%s: ServersChanged = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
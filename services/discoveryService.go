@@ -0,0 +1,85 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+)
+
+// DiscoveryService: Structure used to store the discoveryService functions
+type DiscoveryService struct {
+	domainRepo    interfaces.IDomainRepository
+	discoveryRepo interfaces.IDiscoveryRepository
+	domainService interfaces.IDomainService
+}
+
+// NewDiscoveryService: Receives the repositories and the domainService interface and stores them in the DiscoveryService structure
+// Params:
+// (domainRepo): Reference to a domainRepo interface
+// (discoveryRepo): Reference to a discoveryRepo interface
+// (domainService): Reference to a domainService interface, used to track the discovered hosts
+// Return:
+// (*DiscoveryService): Reference to the DiscoveryService object
+func NewDiscoveryService(domainRepo interfaces.IDomainRepository, discoveryRepo interfaces.IDiscoveryRepository, domainService interfaces.IDomainService) *DiscoveryService {
+	return &DiscoveryService{domainRepo: domainRepo, discoveryRepo: discoveryRepo, domainService: domainService}
+}
+
+// WithOrg: Returns a copy of the service that only sees, and only tracks, the domains of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IDiscoveryService): Scoped service
+func (s *DiscoveryService) WithOrg(orgID int64) interfaces.IDiscoveryService {
+	return &DiscoveryService{domainRepo: s.domainRepo.WithOrg(orgID), discoveryRepo: s.discoveryRepo, domainService: s.domainService.WithOrg(orgID)}
+}
+
+// GetDiscovered: Returns a JSON object with the hosts discovered in the certificates of a domain, flagging the ones already tracked
+// Params:
+// (hostPath): Host of the parent domain
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *DiscoveryService) GetDiscovered(hostPath string) ([]byte, error) {
+	domain, err := s.domainRepo.FindByUrl(hostPath)
+	if err != nil {
+		return nil, errors.New("Domain not found")
+	}
+	hosts, err := s.discoveryRepo.FindByDomain(domain.Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range hosts {
+		if _, findErr := s.domainRepo.FindByUrl(host.Name); findErr == nil {
+			host.Tracked = true
+		}
+	}
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": hosts})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// TrackDiscovered: Adds a discovered host as a tracked domain by analyzing it
+// Params:
+// (hostPath): Host of the parent domain
+// (name): Name of the discovered host
+// Return:
+// ([]byte): JSON object with the new domain
+// (error): Error if the process fails
+func (s *DiscoveryService) TrackDiscovered(hostPath string, name string) ([]byte, error) {
+	domain, err := s.domainRepo.FindByUrl(hostPath)
+	if err != nil {
+		return nil, errors.New("Domain not found")
+	}
+	host, err := s.discoveryRepo.FindByName(domain.Id, strings.ToLower(name))
+	if err != nil {
+		return nil, errors.New("Host was not discovered for this domain")
+	}
+	if _, findErr := s.domainRepo.FindByUrl(host.Name); findErr == nil {
+		return nil, errors.New("Host is already tracked")
+	}
+	return s.domainService.CheckDomain(host.Name)
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+	"github.com/miekg/dns"
+)
+
+// DnsCollector: Structure used to store the nameserver queried by the collector
+type DnsCollector struct {
+	nameserver string
+	client     *dns.Client
+	tcpClient  *dns.Client
+}
+
+// NewDnsCollector: Receives the address of the nameserver and stores it in the DnsCollector structure
+// Params:
+// (nameserver): Address of the nameserver, such as 127.0.0.1:5353. If empty, the first nameserver of /etc/resolv.conf is used
+// Return:
+// (*DnsCollector): Reference to the DnsCollector object
+// (error): Error if the nameserver cannot be determined
+func NewDnsCollector(nameserver string) (*DnsCollector, error) {
+	if nameserver == "" {
+		config, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+		if err != nil || len(config.Servers) == 0 {
+			return nil, errors.New("No nameserver configured")
+		}
+		nameserver = net.JoinHostPort(config.Servers[0], config.Port)
+	}
+	if _, _, err := net.SplitHostPort(nameserver); err != nil {
+		nameserver = net.JoinHostPort(nameserver, "53")
+	}
+	return &DnsCollector{nameserver: nameserver, client: &dns.Client{Timeout: 5 * time.Second}, tcpClient: &dns.Client{Net: "tcp", Timeout: 5 * time.Second}}, nil
+}
+
+// Collect: Queries the A, AAAA, CNAME, MX, NS, CAA and TXT records of a host, and the DMARC record of its _dmarc subdomain
+// Params:
+// (host): Host to be queried
+// Return:
+// (*models.DnsRecords): Reference to the collected records
+// (error): Error if the nameserver cannot be reached
+func (c *DnsCollector) Collect(host string) (*models.DnsRecords, error) {
+	host = hostname(host)
+	records := &models.DnsRecords{A: []string{}, AAAA: []string{}, CnameChain: []string{}, MX: []models.MxRecord{}, NS: []string{}, CAA: []models.CaaRecord{}, TXT: []string{}, Changes: []string{}, CollectedAt: time.Now().Unix()}
+	target := dns.Fqdn(host)
+	for ii := 0; ii < 10; ii++ {
+		answers, err := c.query(target, dns.TypeCNAME)
+		if err != nil {
+			return nil, err
+		}
+		if len(answers) == 0 {
+			break
+		}
+		cname, ok := answers[0].(*dns.CNAME)
+		if ok == false {
+			break
+		}
+		records.CnameChain = append(records.CnameChain, strings.TrimSuffix(cname.Target, "."))
+		target = cname.Target
+	}
+	answers, err := c.query(dns.Fqdn(host), dns.TypeA)
+	if err != nil {
+		return nil, err
+	}
+	for _, answer := range answers {
+		if record, ok := answer.(*dns.A); ok {
+			records.A = append(records.A, record.A.String())
+		}
+	}
+	if answers, err = c.query(dns.Fqdn(host), dns.TypeAAAA); err != nil {
+		return nil, err
+	}
+	for _, answer := range answers {
+		if record, ok := answer.(*dns.AAAA); ok {
+			records.AAAA = append(records.AAAA, record.AAAA.String())
+		}
+	}
+	if answers, err = c.query(dns.Fqdn(host), dns.TypeMX); err != nil {
+		return nil, err
+	}
+	for _, answer := range answers {
+		if record, ok := answer.(*dns.MX); ok {
+			records.MX = append(records.MX, models.MxRecord{Host: strings.TrimSuffix(record.Mx, "."), Preference: record.Preference})
+		}
+	}
+	if answers, err = c.query(dns.Fqdn(host), dns.TypeNS); err != nil {
+		return nil, err
+	}
+	for _, answer := range answers {
+		if record, ok := answer.(*dns.NS); ok {
+			records.NS = append(records.NS, strings.TrimSuffix(record.Ns, "."))
+		}
+	}
+	if answers, err = c.query(dns.Fqdn(host), dns.TypeCAA); err != nil {
+		return nil, err
+	}
+	for _, answer := range answers {
+		if record, ok := answer.(*dns.CAA); ok {
+			records.CAA = append(records.CAA, models.CaaRecord{Flag: record.Flag, Tag: record.Tag, Value: record.Value})
+		}
+	}
+	if answers, err = c.query(dns.Fqdn(host), dns.TypeTXT); err != nil {
+		return nil, err
+	}
+	for _, answer := range answers {
+		if record, ok := answer.(*dns.TXT); ok {
+			txt := strings.Join(record.Txt, "")
+			records.TXT = append(records.TXT, txt)
+			if strings.HasPrefix(strings.ToLower(txt), "v=spf1") {
+				records.SPF = txt
+			}
+		}
+	}
+	if answers, err = c.query(dns.Fqdn("_dmarc."+host), dns.TypeTXT); err != nil {
+		return nil, err
+	}
+	for _, answer := range answers {
+		if record, ok := answer.(*dns.TXT); ok {
+			txt := strings.Join(record.Txt, "")
+			if strings.HasPrefix(strings.ToLower(txt), "v=dmarc1") {
+				records.DMARC = txt
+			}
+		}
+	}
+	sort.Strings(records.A)
+	sort.Strings(records.AAAA)
+	sort.Strings(records.NS)
+	sort.Strings(records.TXT)
+	sort.Slice(records.MX, func(i, j int) bool {
+		if records.MX[i].Preference != records.MX[j].Preference {
+			return records.MX[i].Preference < records.MX[j].Preference
+		}
+		return records.MX[i].Host < records.MX[j].Host
+	})
+	sort.Slice(records.CAA, func(i, j int) bool {
+		return records.CAA[i].Tag+records.CAA[i].Value < records.CAA[j].Tag+records.CAA[j].Value
+	})
+	return records, nil
+}
+
+// query: Makes a single recursive query to the nameserver and returns the answers that match the type.
+// A truncated UDP response, such as a large TXT set, is queried again over TCP
+// Params:
+// (name): Fully qualified name to be queried
+// (recordType): DNS record type
+// Return:
+// ([]dns.RR): Answers of the query. Empty if the name does not exist
+// (error): Error if the nameserver cannot be reached or fails
+func (c *DnsCollector) query(name string, recordType uint16) ([]dns.RR, error) {
+	message := new(dns.Msg)
+	message.SetQuestion(name, recordType)
+	message.RecursionDesired = true
+	response, _, err := c.client.Exchange(message, c.nameserver)
+	if err == nil && response.Truncated {
+		response, _, err = c.tcpClient.Exchange(message, c.nameserver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response.Rcode == dns.RcodeNameError {
+		return []dns.RR{}, nil
+	}
+	if response.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("DNS query %s %s failed with %s", name, dns.TypeToString[recordType], dns.RcodeToString[response.Rcode])
+	}
+	answers := make([]dns.RR, 0)
+	for _, answer := range response.Answer {
+		if answer.Header().Rrtype == recordType {
+			answers = append(answers, answer)
+		}
+	}
+	return answers, nil
+}
+
+// diffDnsRecords: Auxiliary function that lists the record sets that differ between two collections
+// Params:
+// (previous): Reference to the records of the previous scan, nil if there was none
+// (current): Reference to the records of the current scan
+// Return:
+// ([]string): Description of each change
+func diffDnsRecords(previous *models.DnsRecords, current *models.DnsRecords) []string {
+	changes := make([]string, 0)
+	if previous == nil {
+		return changes
+	}
+	compare := func(name string, before interface{}, after interface{}) {
+		if fmt.Sprint(before) != fmt.Sprint(after) {
+			changes = append(changes, fmt.Sprintf("%s records changed from %v to %v", name, before, after))
+		}
+	}
+	compare("A", previous.A, current.A)
+	compare("AAAA", previous.AAAA, current.AAAA)
+	compare("CNAME", previous.CnameChain, current.CnameChain)
+	compare("MX", previous.MX, current.MX)
+	compare("NS", previous.NS, current.NS)
+	compare("CAA", previous.CAA, current.CAA)
+	compare("TXT", previous.TXT, current.TXT)
+	compare("DMARC", previous.DMARC, current.DMARC)
+	return changes
+}
+
+// hostname: Auxiliary function that removes the scheme, port and path of a url
+// Params:
+// (url): Url or host
+// Return:
+// (string): Host name
+func hostname(url string) string {
+	host := url
+	if index := strings.Index(host, "://"); index >= 0 {
+		host = host[index+3:]
+	}
+	if index := strings.IndexAny(host, "/?#"); index >= 0 {
+		host = host[:index]
+	}
+	if splitHost, _, err := net.SplitHostPort(host); err == nil {
+		host = splitHost
+	}
+	return strings.TrimSuffix(host, ".")
+}
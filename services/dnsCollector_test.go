@@ -0,0 +1,184 @@
+package services
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+	"github.com/miekg/dns"
+)
+
+// fakeZone: Records served by the fake nameserver, by name and type. The UDP answers of the names in
+// truncated only carry the TC flag, so the client has to retry over TCP
+type fakeZone struct {
+	mutex     sync.Mutex
+	records   map[string][]dns.RR
+	truncated map[string]bool
+}
+
+func (z *fakeZone) set(t *testing.T, records ...string) {
+	z.mutex.Lock()
+	defer z.mutex.Unlock()
+	z.records = make(map[string][]dns.RR)
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			t.Fatalf("dns.NewRR(%q): %v", record, err)
+		}
+		key := rr.Header().Name + "/" + dns.TypeToString[rr.Header().Rrtype]
+		z.records[key] = append(z.records[key], rr)
+	}
+}
+
+func (z *fakeZone) ServeDNS(w dns.ResponseWriter, request *dns.Msg) {
+	z.mutex.Lock()
+	defer z.mutex.Unlock()
+	response := new(dns.Msg)
+	response.SetReply(request)
+	question := request.Question[0]
+	key := question.Name + "/" + dns.TypeToString[question.Qtype]
+	_, isTcp := w.RemoteAddr().(*net.TCPAddr)
+	if z.truncated[key] && isTcp == false {
+		response.Truncated = true
+	} else if answers, ok := z.records[key]; ok {
+		response.Answer = answers
+	} else {
+		exists := false
+		for name := range z.records {
+			exists = exists || strings.HasPrefix(name, question.Name+"/")
+		}
+		if exists == false {
+			response.Rcode = dns.RcodeNameError
+		}
+	}
+	w.WriteMsg(response)
+}
+
+// startFakeNameserver: Serves the zone over UDP and TCP on the same loopback port and returns its address
+func startFakeNameserver(t *testing.T, zone *fakeZone) string {
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.ListenPacket: %v", err)
+	}
+	listener, err := net.Listen("tcp", packetConn.LocalAddr().String())
+	if err != nil {
+		packetConn.Close()
+		t.Fatalf("net.Listen: %v", err)
+	}
+	servers := []*dns.Server{{PacketConn: packetConn, Handler: zone}, {Listener: listener, Handler: zone}}
+	for _, server := range servers {
+		started := make(chan bool)
+		server.NotifyStartedFunc = func() { close(started) }
+		go server.ActivateAndServe()
+		<-started
+	}
+	t.Cleanup(func() {
+		for _, server := range servers {
+			server.Shutdown()
+		}
+	})
+	return packetConn.LocalAddr().String()
+}
+
+func TestDnsCollectorCollect(t *testing.T) {
+	zone := &fakeZone{truncated: map[string]bool{"example.test./TXT": true}}
+	zone.set(t,
+		"example.test. 300 IN CNAME edge.cdn.test.",
+		"edge.cdn.test. 300 IN CNAME lb.cdn.test.",
+		"example.test. 300 IN A 192.0.2.2",
+		"example.test. 300 IN A 192.0.2.1",
+		"example.test. 300 IN AAAA 2001:db8::1",
+		"example.test. 300 IN MX 20 mx2.example.test.",
+		"example.test. 300 IN MX 10 mx1.example.test.",
+		"example.test. 300 IN NS ns2.example.test.",
+		"example.test. 300 IN NS ns1.example.test.",
+		`example.test. 300 IN CAA 0 issue "letsencrypt.org"`,
+		`example.test. 300 IN TXT "v=spf1 include:_spf.example.test " "-all"`,
+		`example.test. 300 IN TXT "verification=abc"`,
+		`_dmarc.example.test. 300 IN TXT "v=DMARC1; p=reject"`,
+	)
+	collector, err := NewDnsCollector(startFakeNameserver(t, zone))
+	if err != nil {
+		t.Fatalf("NewDnsCollector: %v", err)
+	}
+
+	records, err := collector.Collect("https://example.test/login")
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	expected := &models.DnsRecords{
+		A:          []string{"192.0.2.1", "192.0.2.2"},
+		AAAA:       []string{"2001:db8::1"},
+		CnameChain: []string{"edge.cdn.test", "lb.cdn.test"},
+		MX:         []models.MxRecord{{Host: "mx1.example.test", Preference: 10}, {Host: "mx2.example.test", Preference: 20}},
+		NS:         []string{"ns1.example.test", "ns2.example.test"},
+		CAA:        []models.CaaRecord{{Flag: 0, Tag: "issue", Value: "letsencrypt.org"}},
+		TXT:        []string{"v=spf1 include:_spf.example.test -all", "verification=abc"},
+		SPF:        "v=spf1 include:_spf.example.test -all",
+		DMARC:      "v=DMARC1; p=reject",
+		Changes:    []string{},
+	}
+	expected.CollectedAt = records.CollectedAt
+	if reflect.DeepEqual(records, expected) == false {
+		t.Errorf("Collect = %+v, want %+v", records, expected)
+	}
+	if changes := diffDnsRecords(records, records); len(changes) != 0 {
+		t.Errorf("diffDnsRecords of the same records = %v, want none", changes)
+	}
+
+	zone.set(t,
+		"example.test. 300 IN A 192.0.2.1",
+		"example.test. 300 IN A 192.0.2.9",
+		"example.test. 300 IN AAAA 2001:db8::1",
+		"example.test. 300 IN MX 10 mx1.example.test.",
+		"example.test. 300 IN MX 20 mx2.example.test.",
+		"example.test. 300 IN NS ns1.example.test.",
+		"example.test. 300 IN NS ns2.example.test.",
+		`example.test. 300 IN CAA 0 issue "letsencrypt.org"`,
+		`example.test. 300 IN TXT "verification=abc"`,
+		`example.test. 300 IN TXT "v=spf1 include:_spf.example.test " "-all"`,
+	)
+	current, err := collector.Collect("example.test")
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	changes := diffDnsRecords(records, current)
+	changed := make([]string, 0)
+	for _, change := range changes {
+		changed = append(changed, strings.Fields(change)[0])
+	}
+	if want := []string{"A", "CNAME", "DMARC"}; reflect.DeepEqual(changed, want) == false {
+		t.Errorf("diffDnsRecords = %v, want changes of %v", changes, want)
+	}
+	if changes := diffDnsRecords(nil, current); len(changes) != 0 {
+		t.Errorf("diffDnsRecords without previous records = %v, want none", changes)
+	}
+}
+
+func TestDnsCollectorFailsOnServerError(t *testing.T) {
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, request *dns.Msg) {
+		response := new(dns.Msg)
+		response.SetRcode(request, dns.RcodeServerFailure)
+		w.WriteMsg(response)
+	})
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.ListenPacket: %v", err)
+	}
+	server := &dns.Server{PacketConn: packetConn, Handler: handler}
+	started := make(chan bool)
+	server.NotifyStartedFunc = func() { close(started) }
+	go server.ActivateAndServe()
+	<-started
+	defer server.Shutdown()
+	collector, err := NewDnsCollector(packetConn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("NewDnsCollector: %v", err)
+	}
+	if _, err = collector.Collect("example.test"); err == nil {
+		t.Fatal("Collect succeeded against a failing nameserver")
+	}
+}
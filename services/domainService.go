@@ -1,48 +1,103 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"github.com/JonatanOrdonez/tr-backend/metrics"
+	"github.com/JonatanOrdonez/tr-backend/tracing"
 
 	"github.com/JonatanOrdonez/tr-backend/models"
-	"github.com/badoux/goscraper"
 	"github.com/likexian/whois-go"
 	"github.com/valyala/fasthttp"
+	otelAttribute "go.opentelemetry.io/otel/attribute"
 )
 
 // DomainService: Structure used to store the domainService functions
 type DomainService struct {
-	domainRepo interfaces.IDomainRepository
+	domainRepo      interfaces.IDomainRepository
+	publisher       interfaces.IEventPublisher
+	changeDetector  interfaces.IChangeDetector
+	policyEvaluator interfaces.IPolicyEvaluator
+	dnsCollector    interfaces.IDnsCollector
+	whoisCollector  interfaces.IWhoisCollector
+	discoverer      interfaces.IHostDiscoverer
+	orgID           int64
+	ctx             context.Context
+	logger          interfaces.ILogger
 }
 
-// NewDomainService: Receives a reference to the domainRepo interface and stores it in the DomainService structure
+// NewDomainService: Receives a reference to the domainRepo, publisher, changeDetector, policyEvaluator, dnsCollector, whoisCollector, discoverer and logger interfaces and stores them in the DomainService structure
 // Params:
 // (domainRepo): Reference to a domainRepo interface
+// (publisher): Reference to the publisher that receives the domain events
+// (changeDetector): Reference to the policy that decides when the servers changed
+// (policyEvaluator): Reference to the evaluator that computes the compliance block after each scan
+// (dnsCollector): Reference to the collector of the DNS records
+// (whoisCollector): Reference to the collector of the domain registration
+// (discoverer): Reference to the discoverer of the hosts named by the certificates
+// (logger): Reference to the logger, the requests to SSL Labs, WHOIS and the home pages are written at the debug level
 // Return:
 // (*DomainService): Reference to the BaseHandler object
-func NewDomainService(domainRepo interfaces.IDomainRepository) *DomainService {
-	return &DomainService{domainRepo: domainRepo}
+func NewDomainService(domainRepo interfaces.IDomainRepository, publisher interfaces.IEventPublisher, changeDetector interfaces.IChangeDetector, policyEvaluator interfaces.IPolicyEvaluator, dnsCollector interfaces.IDnsCollector, whoisCollector interfaces.IWhoisCollector, discoverer interfaces.IHostDiscoverer, logger interfaces.ILogger) *DomainService {
+	return &DomainService{domainRepo: domainRepo, publisher: publisher, changeDetector: changeDetector, policyEvaluator: policyEvaluator, dnsCollector: dnsCollector, whoisCollector: whoisCollector, discoverer: discoverer, ctx: context.Background(), logger: logger}
+}
+
+// WithOrg: Returns a copy of the service that only sees, and only adds, the domains of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IDomainService): Scoped service
+func (s *DomainService) WithOrg(orgID int64) interfaces.IDomainService {
+	scoped := *s
+	scoped.domainRepo = s.domainRepo.WithOrg(orgID)
+	scoped.orgID = orgID
+	return &scoped
+}
+
+// WithContext: Returns a copy of the service whose spans, and the queries of its repository, are children of a context
+// Params:
+// (ctx): Context of the caller, such as the context of the request span
+// Return:
+// (interfaces.IDomainService): Service bound to the context
+func (s *DomainService) WithContext(ctx context.Context) interfaces.IDomainService {
+	scoped := *s
+	scoped.domainRepo = s.domainRepo.WithContext(ctx)
+	scoped.ctx = ctx
+	return &scoped
 }
 
 // ResponseDomains: Returns a JSON object domain Slice
+// Params:
+// (tags): Tags the domains must have, every domain if empty
 // Return:
 // ([]byte): JSON object
 // (error): Error if the process fails
-func (s *DomainService) GetDomains() ([]byte, error) {
-	domains, err := s.domainRepo.GetAll()
+func (s *DomainService) GetDomains(tags []string) ([]byte, error) {
+	var domains []*models.Domain
+	var err error
+	if len(tags) == 0 {
+		domains, err = s.domainRepo.GetAll()
+	} else {
+		if tags, err = normalizeTags(tags); err != nil {
+			return nil, err
+		}
+		domains, err = s.domainRepo.FindByTags(tags)
+	}
 	if err != nil {
 		return nil, err
 	}
-	items := &models.Items{domains}
+	items := &models.Items{Items: domains}
 	jsonBody, jsonError := json.Marshal(items)
 	if jsonError != nil {
 		return nil, jsonError
@@ -54,18 +109,30 @@ func (s *DomainService) GetDomains() ([]byte, error) {
 // If the request has the host query param empty (""), the redirection is made to ResponseDomains
 // If a domain is not found that matches its url as host, then the redirection is made to AddDomain function
 // If there is a domain such that the url equals host, then the redirection is made to UpdateDomain function
+// The service must be scoped to an organization with WithOrg, the new domains belong to it
 // Params:
 // (hostPath): Host value of the path param
 // Return:
 // ([]byte): JSON object
 // (error): Error if the process fails
 func (s *DomainService) CheckDomain(hostPath string) ([]byte, error) {
-	domain, domainErr := s.domainRepo.FindByUrl(hostPath)
+	if s.orgID <= 0 {
+		return nil, errors.New("Organization is required")
+	}
+	metrics.ScanStarted()
+	defer metrics.ScanFinished()
+	ctx, span := tracing.Start(s.ctx, "CheckDomain", otelAttribute.String("domain.host", hostPath), otelAttribute.Int64("org.id", s.orgID))
+	traced := s.WithContext(ctx).(*DomainService)
+	var jsonBody []byte
+	var err error
+	domain, domainErr := traced.domainRepo.FindByUrl(hostPath)
 	if domainErr != nil {
-		return s.AddDomain(hostPath)
+		jsonBody, err = traced.AddDomain(hostPath)
 	} else {
-		return s.UpdateDomain(hostPath, domain)
+		jsonBody, err = traced.UpdateDomain(hostPath, domain)
 	}
+	tracing.End(span, err)
+	return jsonBody, err
 }
 
 // AddDomain: Creates a new domain in the database, according to the requirements of the test.
@@ -84,7 +151,8 @@ func (s *DomainService) AddDomain(hostPath string) ([]byte, error) {
 	if ssllabs.Status == "ERROR" {
 		servers := []models.Server{}
 		UpdatedAt := time.Now().Unix()
-		newDomain := &models.Domain{servers, ssllabs.Endpoints, false, "", "", "", "", true, 1, hostPath, UpdatedAt}
+		newDomain := &models.Domain{Servers: servers, Endpoints: ssllabs.Endpoints, IsDown: true, Id: 1, OrgId: s.orgID, Url: hostPath, UpdatedAt: UpdatedAt}
+		s.enrichDomain(newDomain, nil, nil)
 		id, saveDomainError := s.domainRepo.Save(newDomain)
 		if saveDomainError != nil {
 			return nil, saveDomainError
@@ -109,7 +177,8 @@ func (s *DomainService) AddDomain(hostPath string) ([]byte, error) {
 		servers := []models.Server{}
 		endpoints := []models.Endpoint{}
 		UpdatedAt := time.Now().Unix()
-		newDomain := &models.Domain{servers, endpoints, false, "", "", "", "", true, 1, hostPath, UpdatedAt}
+		newDomain := &models.Domain{Servers: servers, Endpoints: endpoints, IsDown: true, Id: 1, OrgId: s.orgID, Url: hostPath, UpdatedAt: UpdatedAt}
+		s.enrichDomain(newDomain, nil, nil)
 		id, saveDomainError := s.domainRepo.Save(newDomain)
 		if saveDomainError != nil {
 			return nil, saveDomainError
@@ -128,14 +197,19 @@ func (s *DomainService) AddDomain(hostPath string) ([]byte, error) {
 	if fetchSDError != nil {
 		return nil, fetchSDError
 	}
-	sslGrade := ""
+	sslGrade := models.GradeNotGraded
 	lowerServer, lsErr := s.GetLowerServer(servers)
 	if lsErr == nil {
 		sslGrade = lowerServer.SslGrade
 	}
-	logo, title, _ := s.ScrapPage(hostPath)
+	logo, title := "", ""
+	page, pageErr := s.FetchPage(hostPath)
+	if pageErr == nil {
+		logo, title = page.Logo, page.Title
+	}
 	UpdatedAt := time.Now().Unix()
-	newDomain := &models.Domain{servers, ssllabs.Endpoints, false, sslGrade, sslGrade, logo, title, false, 1, hostPath, UpdatedAt}
+	newDomain := &models.Domain{Servers: servers, Endpoints: ssllabs.Endpoints, SslGrade: sslGrade, PreviousSslGrade: sslGrade, Logo: logo, Title: title, Id: 1, OrgId: s.orgID, Url: hostPath, UpdatedAt: UpdatedAt}
+	s.enrichDomain(newDomain, nil, page)
 	id, saveDomainError := s.domainRepo.Save(newDomain)
 	if saveDomainError != nil {
 		return nil, saveDomainError
@@ -144,6 +218,7 @@ func (s *DomainService) AddDomain(hostPath string) ([]byte, error) {
 	if queryErr != nil {
 		return nil, queryErr
 	}
+	s.discoverer.Discover(domainEntity)
 	jsonBody, jsonError := json.Marshal(domainEntity)
 	if jsonError != nil {
 		return nil, jsonError
@@ -152,6 +227,7 @@ func (s *DomainService) AddDomain(hostPath string) ([]byte, error) {
 }
 
 // UpdateDomain: Update a new domain in the database, according to the requirements of the test.
+// The servers are only fetched again when the change detection policy requires it
 // Params:
 // (hostPath): Host value of the path param
 // (domain): Reference to the domain
@@ -184,22 +260,24 @@ func (s *DomainService) UpdateDomain(hostPath string, domain *models.Domain) ([]
 		}
 		return jsonBody, nil
 	}
-	endpointsAreEquals := s.EndpointsAreEqual(domain.Endpoints, ssllabs.Endpoints)
-	currentDate := time.Unix(time.Now().Unix(), 0)
-	updatedAt := time.Unix(domain.UpdatedAt, 0)
-	elapsed := currentDate.Sub(updatedAt).Hours()
-	if endpointsAreEquals == false && elapsed < 1 {
+	endpointsChanged := s.changeDetector.EndpointsChanged(domain.Endpoints, ssllabs.Endpoints)
+	if s.changeDetector.NeedsRefresh(domain.UpdatedAt, endpointsChanged, time.Now()) {
 		servers, fetchSDError := s.FetchServersData(ssllabs.Endpoints)
 		if fetchSDError != nil {
 			return nil, fetchSDError
 		}
 		lowerServer, lsErr := s.GetLowerServer(servers)
-		sslGrade := ""
+		sslGrade := models.GradeNotGraded
 		if lsErr == nil {
 			sslGrade = lowerServer.SslGrade
 		}
 		newUpdatedAt := time.Now().Unix()
-		newDomain := &models.Domain{servers, ssllabs.Endpoints, true, sslGrade, domain.SslGrade, domain.Logo, domain.Title, false, domain.Id, hostPath, newUpdatedAt}
+		newDomain := &models.Domain{Servers: servers, Endpoints: ssllabs.Endpoints, ServersChanged: s.changeDetector.ServersChanged(domain.Servers, servers), SslGrade: sslGrade, PreviousSslGrade: domain.SslGrade, Logo: domain.Logo, Title: domain.Title, Id: domain.Id, OrgId: domain.OrgId, Url: hostPath, UpdatedAt: newUpdatedAt}
+		page, _ := s.FetchPage(hostPath)
+		if page != nil {
+			newDomain.Logo, newDomain.Title = page.Logo, page.Title
+		}
+		s.enrichDomain(newDomain, domain, page)
 		id, updatedErr := s.domainRepo.Update(newDomain)
 		if updatedErr != nil {
 			return nil, updatedErr
@@ -208,6 +286,8 @@ func (s *DomainService) UpdateDomain(hostPath string, domain *models.Domain) ([]
 		if queryErr != nil {
 			return nil, queryErr
 		}
+		s.discoverer.Discover(domainEntity)
+		s.publishChanges(domain, domainEntity)
 		jsonBody, jsonError := json.Marshal(domainEntity)
 		if jsonError != nil {
 			return nil, jsonError
@@ -215,6 +295,11 @@ func (s *DomainService) UpdateDomain(hostPath string, domain *models.Domain) ([]
 		return jsonBody, nil
 	} else {
 		domain.ServersChanged = false
+		page, _ := s.FetchPage(hostPath)
+		if page != nil {
+			domain.Logo, domain.Title = page.Logo, page.Title
+		}
+		s.enrichDomain(domain, domain, page)
 		id, updatedErr := s.domainRepo.Update(domain)
 		if updatedErr != nil {
 			return nil, updatedErr
@@ -223,6 +308,7 @@ func (s *DomainService) UpdateDomain(hostPath string, domain *models.Domain) ([]
 		if queryErr != nil {
 			return nil, queryErr
 		}
+		s.discoverer.Discover(domainEntity)
 		jsonBody, jsonError := json.Marshal(domainEntity)
 		if jsonError != nil {
 			return nil, jsonError
@@ -231,6 +317,64 @@ func (s *DomainService) UpdateDomain(hostPath string, domain *models.Domain) ([]
 	}
 }
 
+// enrichDomain: Adds to a domain about to be stored the data collected besides SSL Labs: the DNS records,
+// flagged against the previous scan, the WHOIS registration, the security headers audit of the home page and
+// the compliance block, evaluated last so the policies see everything
+// Params:
+// (domain): Reference to the domain to be stored
+// (previous): Reference to the stored version of the domain, nil if it is new
+// (page): Reference to the home page fetched during the scan, nil if it could not be fetched
+func (s *DomainService) enrichDomain(domain *models.Domain, previous *models.Domain, page *models.Page) {
+	var previousDns *models.DnsRecords
+	var previousHeaders *models.HeadersAudit
+	var previousRegistration *models.Registration
+	if previous != nil {
+		previousDns, previousHeaders, previousRegistration = previous.Dns, previous.Headers, previous.Registration
+		domain.Tags = previous.Tags
+	}
+	dnsRecords, dnsErr := s.dnsCollector.Collect(domain.Url)
+	if dnsErr == nil {
+		dnsRecords.Changes = diffDnsRecords(previousDns, dnsRecords)
+		dnsRecords.Changed = len(dnsRecords.Changes) > 0
+		domain.Dns = dnsRecords
+	} else {
+		s.logger.Warn(s.ctx, "DNS lookup failed, the previous records are kept", "host", domain.Url, "error", dnsErr)
+		domain.Dns = previousDns
+	}
+	registration, whoisErr := s.whoisCollector.Collect(domain.Url, previousRegistration)
+	if whoisErr == nil {
+		domain.Registration = registration
+	} else {
+		s.logger.Warn(s.ctx, "WHOIS lookup failed, the previous registration is kept", "host", domain.Url, "error", whoisErr)
+		domain.Registration = previousRegistration
+	}
+	if page != nil {
+		domain.Headers = AuditHeaders(page.Headers)
+	} else {
+		domain.Headers = previousHeaders
+	}
+	domain.Compliance = s.policyEvaluator.Evaluate(domain)
+}
+
+// publishChanges: Compares a domain before and after an update and publishes the grade and server events
+// Params:
+// (previous): Reference to the domain before the update
+// (current): Reference to the domain after the update
+func (s *DomainService) publishChanges(previous *models.Domain, current *models.Domain) {
+	now := time.Now().Unix()
+	if current.ServersChanged {
+		s.publisher.Publish(&models.Event{Type: models.EventServersChanged, Url: current.Url, OccurredAt: now, Domain: current})
+	}
+	if previous.SslGrade.IsGraded() == false || current.SslGrade.IsGraded() == false {
+		return
+	}
+	if current.SslGrade.Compare(previous.SslGrade) < 0 {
+		s.publisher.Publish(&models.Event{Type: models.EventGradeDowngraded, Url: current.Url, OccurredAt: now, Domain: current})
+	} else if current.SslGrade.Compare(previous.SslGrade) > 0 {
+		s.publisher.Publish(&models.Event{Type: models.EventGradeUpgraded, Url: current.Url, OccurredAt: now, Domain: current})
+	}
+}
+
 // CheckDomainInSsllabs: Takes a domain url and makes a request to the Ssllabs api for obtain information
 // Params:
 // (url): URl of the domain you are looking for
@@ -238,27 +382,81 @@ func (s *DomainService) UpdateDomain(hostPath string, domain *models.Domain) ([]
 // (*models.Ssllabs): Reference to the response object
 // (error): Error if the process fails
 func (s *DomainService) CheckDomainInSsllabs(url string) (*models.Ssllabs, error) {
-	resp, err := http.Get(fmt.Sprintf("https://api.ssllabs.com/api/v3/analyze?host=%s", url))
+	ctx, span := tracing.Start(s.ctx, "CheckDomainInSsllabs", otelAttribute.String("domain.host", url))
+	start := time.Now()
+	ssllabs, status, err := requestSsllabs(ctx, url)
+	s.logger.Debug(s.ctx, "SSL Labs request", "host", url, "status", status, "duration_ms", time.Since(start).Milliseconds(), "error", err)
+	span.SetAttributes(otelAttribute.String("ssllabs.status", status))
+	tracing.End(span, err)
+	return ssllabs, err
+}
+
+// requestSsllabs: Auxiliary function that makes a request to the Ssllabs api and records it in the metrics
+// Params:
+// (ctx): Context of the request
+// (url): URl of the domain you are looking for
+// Return:
+// (*models.Ssllabs): Reference to the response object
+// (string): Assessment status, the HTTP status code if it is not 200, network_error or invalid_response
+// (error): Error if the process fails
+func requestSsllabs(ctx context.Context, url string) (*models.Ssllabs, string, error) {
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://api.ssllabs.com/api/v3/analyze?host=%s&all=done", url), nil)
 	if err != nil {
-		return nil, err
+		return nil, "invalid_request", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		metrics.ObserveSsllabs("network_error", time.Since(start))
+		return nil, "network_error", err
 	}
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		metrics.ObserveSsllabs("network_error", time.Since(start))
+		return nil, "network_error", err
 	}
 	if resp.StatusCode == 200 {
 		var ssllabs *models.Ssllabs
 		unmarshalError := json.Unmarshal(body, &ssllabs)
 		if unmarshalError != nil {
-			return nil, unmarshalError
+			metrics.ObserveSsllabs("invalid_response", time.Since(start))
+			return nil, "invalid_response", unmarshalError
+		}
+		metrics.ObserveSsllabs(ssllabs.Status, time.Since(start))
+		attachCertificates(ssllabs)
+		return ssllabs, ssllabs.Status, nil
+	}
+	status := strconv.Itoa(resp.StatusCode)
+	metrics.ObserveSsllabs(status, time.Since(start))
+	return nil, status, errors.New("Invalid host")
+}
+
+// attachCertificates: Auxiliary function that stores in each endpoint the leaf certificate of its first chain
+// Params:
+// (ssllabs): Reference to the Ssllabs response
+func attachCertificates(ssllabs *models.Ssllabs) {
+	if ssllabs == nil {
+		return
+	}
+	for ii := range ssllabs.Endpoints {
+		endpoint := &ssllabs.Endpoints[ii]
+		if endpoint.Details == nil || len(endpoint.Details.CertChains) == 0 || len(endpoint.Details.CertChains[0].CertIds) == 0 {
+			continue
+		}
+		leafID := endpoint.Details.CertChains[0].CertIds[0]
+		for jj := range ssllabs.Certs {
+			if ssllabs.Certs[jj].Id == leafID {
+				cert := ssllabs.Certs[jj]
+				endpoint.Certificate = &cert
+				break
+			}
 		}
-		return ssllabs, nil
 	}
-	return nil, errors.New("Invalid host")
 }
 
-// GetLowerServer: Takes a server slice and looks for the one with the lowest SSL grade
+// GetLowerServer: Takes a server slice and looks for the one with the lowest SSL grade.
+// Servers that are not graded are only returned when no server has a grade
 // Params:
 // ([]models.Server): Server slice
 // Return:
@@ -269,20 +467,14 @@ func (s *DomainService) GetLowerServer(servers []models.Server) (*models.Server,
 		return nil, errors.New("servers array is empty")
 	}
 	var lowerServer *models.Server
-	for _, server := range servers {
-		if lowerServer == nil {
-			lowerServer = &server
+	for ii := range servers {
+		server := &servers[ii]
+		if lowerServer == nil || (lowerServer.SslGrade.IsGraded() == false && server.SslGrade.IsGraded()) {
+			lowerServer = server
+			continue
 		}
-		lowerGrade := 0
-		currentGrade := 0
-		if lowerServer.SslGrade != "" {
-			lowerGrade = int([]rune(lowerServer.SslGrade)[0])
-		}
-		if server.SslGrade != "" {
-			currentGrade = int([]rune(server.SslGrade)[0])
-		}
-		if (lowerGrade - currentGrade) > 0 {
-			lowerServer = &server
+		if server.SslGrade.IsGraded() && server.SslGrade.Compare(lowerServer.SslGrade) < 0 {
+			lowerServer = server
 		}
 	}
 	return lowerServer, nil
@@ -308,9 +500,13 @@ func (s *DomainService) FetchServersData(endpoints []models.Endpoint) ([]models.
 					wg.Done()
 					return
 				}
-				server, err := fetchServerData(v)
+				start := time.Now()
+				server, err := fetchServerData(s.ctx, v)
+				s.logger.Debug(s.ctx, "WHOIS lookup", "ip", v.IpAddress, "duration_ms", time.Since(start).Milliseconds(), "error", err)
 				if err == nil {
 					servers = append(servers, *server)
+				} else {
+					s.logger.Warn(s.ctx, "WHOIS lookup of a server failed, the server is left out", "ip", v.IpAddress, "error", err)
 				}
 			}
 		}(c)
@@ -325,12 +521,17 @@ func (s *DomainService) FetchServersData(endpoints []models.Endpoint) ([]models.
 
 // fetchServerData: Auxiliary function that takes an endpoint object and converts it to a server object
 // Params:
+// (ctx): Context of the caller, parent of the span of the WHOIS query
 // (models.Endpoint): Endpoint object
 // Return:
 // (models.Server): Server reference
 // (error): Error if the process fails
-func fetchServerData(endpoint models.Endpoint) (*models.Server, error) {
+func fetchServerData(ctx context.Context, endpoint models.Endpoint) (*models.Server, error) {
+	_, span := tracing.Start(ctx, "fetchServerData", otelAttribute.String("net.peer.ip", endpoint.IpAddress))
+	start := time.Now()
 	whoisRawData, errRawData := whois.Whois(endpoint.IpAddress)
+	metrics.ObserveWhois("ip", time.Since(start), errRawData)
+	tracing.End(span, errRawData)
 	if errRawData != nil {
 		return nil, errRawData
 	}
@@ -339,7 +540,7 @@ func fetchServerData(endpoint models.Endpoint) (*models.Server, error) {
 	go findWordInData("Country", whoisRawData, countryChanel)
 	go findWordInData("OrgName", whoisRawData, orgChanel)
 	country, owner := <-countryChanel, <-orgChanel
-	server := &models.Server{endpoint.IpAddress, endpoint.Grade, country, owner}
+	server := &models.Server{Address: endpoint.IpAddress, SslGrade: endpoint.Grade, Country: country, Owner: owner, Certificate: toCertificate(endpoint.Certificate)}
 	return server, nil
 }
 
@@ -365,15 +566,69 @@ func findWordInData(word string, rawData string, chanel chan string) {
 // (title): Web page title
 // (error): Error if the process fails
 func (s *DomainService) ScrapPage(url string) (logo string, title string, err error) {
+	page, err := s.FetchPage(url)
+	if err != nil {
+		return "", "", err
+	}
+	return page.Logo, page.Title, nil
+}
+
+// FetchPage: Makes a single request to the home page of a domain and returns its headers, icon and title
+// Params:
+// (url): URl of the domain you are looking for
+// Return:
+// (*models.Page): Reference to the page
+// (error): Error if the process fails
+func (s *DomainService) FetchPage(url string) (*models.Page, error) {
+	ctx, span := tracing.Start(s.ctx, "FetchPage", otelAttribute.String("domain.host", url))
+	start := time.Now()
+	page, err := fetchPage(ctx, url)
+	if page != nil {
+		span.SetAttributes(otelAttribute.Int("http.status_code", page.StatusCode))
+		s.logger.Debug(s.ctx, "Page fetched", "host", url, "url", page.Url, "status", page.StatusCode, "duration_ms", time.Since(start).Milliseconds())
+	}
+	if err != nil {
+		s.logger.Warn(s.ctx, "Page fetch failed, the page data is left out", "host", url, "error", err)
+	}
+	tracing.End(span, err)
+	return page, err
+}
+
+// fetchPage: Auxiliary function that makes the request to the home page and records it in the metrics
+// Params:
+// (ctx): Context of the request
+// (url): URl of the domain you are looking for
+// Return:
+// (*models.Page): Reference to the page
+// (error): Error if the process fails
+func fetchPage(ctx context.Context, url string) (*models.Page, error) {
 	domain := url
 	if strings.HasPrefix(domain, "ht") == false {
 		domain = fmt.Sprintf("http://%s", domain)
 	}
-	scraper, err := goscraper.Scrape(domain, 5)
+	req, err := http.NewRequestWithContext(ctx, "GET", domain, nil)
 	if err != nil {
-		return "", "", nil
+		metrics.ObserveScraper("invalid_url")
+		return nil, err
 	}
-	return scraper.Preview.Icon, scraper.Preview.Title, nil
+	req.Header.Set("User-Agent", "GoScraper")
+	resp, err := pageClient.Do(req)
+	if err != nil {
+		metrics.ObserveScraper("network_error")
+		return nil, err
+	}
+	defer resp.Body.Close()
+	page := &models.Page{Url: resp.Request.URL.String(), StatusCode: resp.StatusCode, Headers: resp.Header}
+	page.Logo, page.Title = parsePage(resp.Request.URL, resp.Body, resp.Header.Get("Content-Type"))
+	switch {
+	case resp.StatusCode >= 400:
+		metrics.ObserveScraper("http_error")
+	case page.Title == "" && page.Logo == "":
+		metrics.ObserveScraper("empty")
+	default:
+		metrics.ObserveScraper("success")
+	}
+	return page, nil
 }
 
 // RaiseError: Takes a ctx reference and responses a JSON error to the client
@@ -382,33 +637,21 @@ func (s *DomainService) ScrapPage(url string) (logo string, title string, err er
 // (errorCode): Error code
 // (errorMessage): Error message
 func (s *DomainService) RaiseError(ctx *fasthttp.RequestCtx, errorCode int, errorMessage string) {
-	errorEntity := &models.Error{errorCode, errorMessage}
+	errorEntity := &models.Error{Code: errorCode, Message: errorMessage}
 	jsonBody, _ := json.Marshal(errorEntity)
 	ctx.SetContentType("application/json; charset=utf-8")
 	ctx.SetStatusCode(errorCode)
 	ctx.Response.SetBody(jsonBody)
 }
 
-// RaiseError: Takes two endpoint slices and compares them
+// EndpointsAreEqual: Takes two endpoint slices and compares them using the change detection policy
 // Params:
 // (endpointA): First endpoint slice
 // (endpointB): Second endpoint slice
 // Return
 // (bool): True if the endpoint slices are equal. False if not
 func (s *DomainService) EndpointsAreEqual(endpointsA []models.Endpoint, endpointsB []models.Endpoint) bool {
-	if len(endpointsA) != len(endpointsB) {
-		return false
-	}
-	for _, endpointA := range endpointsA {
-		endpointB, err := findEndpoint(endpointA.IpAddress, endpointsB)
-		if err != nil {
-			return false
-		}
-		if compareEndpoints(endpointA, endpointB) == false {
-			return false
-		}
-	}
-	return true
+	return s.changeDetector.EndpointsChanged(endpointsA, endpointsB) == false
 }
 
 // findEndpoint: Takes a endpoint ip address and searches it into a endpoint slice
@@ -426,43 +669,3 @@ func findEndpoint(IpAddress string, endpoints []models.Endpoint) (models.Endpoin
 	}
 	return models.Endpoint{}, errors.New("Endpoint not found")
 }
-
-// compareEndpoints: Takes two endpoint objects and compares them
-// Params:
-// (endpointA): First Endpoint object
-// (endpointB): Second Endpoint object
-// Return
-// (bool): True if endpoints are equals. False if not
-func compareEndpoints(endpointA models.Endpoint, endpointB models.Endpoint) bool {
-	if endpointA.ServerName != endpointB.ServerName {
-		return false
-	}
-	if endpointA.StatusMessage != endpointB.StatusMessage {
-		return false
-	}
-	if endpointA.Grade != endpointB.Grade {
-		return false
-	}
-	if endpointA.GradeTrustIgnored != endpointB.GradeTrustIgnored {
-		return false
-	}
-	if endpointA.IsExceptional != endpointB.IsExceptional {
-		return false
-	}
-	if endpointA.Progress != endpointB.Progress {
-		return false
-	}
-	if endpointA.Duration != endpointB.Duration {
-		return false
-	}
-	if endpointA.StatusDetails != endpointB.StatusDetails {
-		return false
-	}
-	if endpointA.StatusDetailsMessage != endpointB.StatusDetailsMessage {
-		return false
-	}
-	if endpointA.Delegation != endpointB.Delegation {
-		return false
-	}
-	return true
-}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// EventBroadcaster: Structure used to store the publishers that receive every domain event
+type EventBroadcaster struct {
+	publishers []interfaces.IEventPublisher
+}
+
+// NewEventBroadcaster: Receives the publishers and stores them in the EventBroadcaster structure
+// Params:
+// (publishers): References to the publishers, such as the webhook and notification services
+// Return:
+// (*EventBroadcaster): Reference to the EventBroadcaster object
+func NewEventBroadcaster(publishers ...interfaces.IEventPublisher) *EventBroadcaster {
+	return &EventBroadcaster{publishers: publishers}
+}
+
+// Publish: Sends the event to every publisher
+// Params:
+// (event): Reference to the event
+func (b *EventBroadcaster) Publish(event *models.Event) {
+	for _, publisher := range b.publishers {
+		publisher.Publish(event)
+	}
+}
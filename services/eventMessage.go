@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// describeEvent: Auxiliary function that builds a human readable title and message for an event
+// Params:
+// (event): Reference to the event
+// Return:
+// (title): Short title of the event
+// (message): Sentence describing the event
+func describeEvent(event *models.Event) (title string, message string) {
+	var previousGrade, currentGrade models.Grade
+	if event.Domain != nil {
+		previousGrade, currentGrade = event.Domain.PreviousSslGrade, event.Domain.SslGrade
+	}
+	switch event.Type {
+	case models.EventGradeDowngraded:
+		return fmt.Sprintf("%s grade downgraded", event.Url), fmt.Sprintf("The SSL grade of %s dropped from %s to %s.", event.Url, previousGrade, currentGrade)
+	case models.EventGradeUpgraded:
+		return fmt.Sprintf("%s grade upgraded", event.Url), fmt.Sprintf("The SSL grade of %s improved from %s to %s.", event.Url, previousGrade, currentGrade)
+	case models.EventServersChanged:
+		return fmt.Sprintf("%s servers changed", event.Url), fmt.Sprintf("The servers behind %s changed since the last scan.", event.Url)
+	case models.EventDomainDown:
+		return fmt.Sprintf("%s is down", event.Url), fmt.Sprintf("%s stopped responding.", event.Url)
+	case models.EventDomainUp:
+		return fmt.Sprintf("%s is up", event.Url), fmt.Sprintf("%s is responding again.", event.Url)
+	}
+	return fmt.Sprintf("%s %s", event.Url, event.Type), fmt.Sprintf("Event %s on %s.", event.Type, event.Url)
+}
+
+// eventColor: Auxiliary function that returns the hex color used by the chat cards of an event
+// Params:
+// (event): Reference to the event
+// Return:
+// (string): Hex color without the # prefix
+func eventColor(event *models.Event) string {
+	switch event.Type {
+	case models.EventGradeDowngraded, models.EventDomainDown:
+		return "D00000"
+	case models.EventGradeUpgraded, models.EventDomainUp:
+		return "2EB886"
+	}
+	return "DAA038"
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// domainExportHeader: Columns of the CSV export with one row per domain
+var domainExportHeader = []string{"url", "ssl_grade", "previous_ssl_grade", "is_down", "servers_changed", "updated_at", "servers"}
+
+// serverExportHeader: Columns of the CSV export with one row per server
+var serverExportHeader = []string{"url", "ssl_grade", "previous_ssl_grade", "is_down", "servers_changed", "updated_at", "address", "server_ssl_grade", "country", "owner"}
+
+// ExportService: Structure used to store the exportService functions
+type ExportService struct {
+	domainRepo interfaces.IDomainRepository
+}
+
+// NewExportService: Receives a reference to the domainRepo interface and stores it in the ExportService structure
+// Params:
+// (domainRepo): Reference to a domainRepo interface
+// Return:
+// (*ExportService): Reference to the ExportService object
+func NewExportService(domainRepo interfaces.IDomainRepository) *ExportService {
+	return &ExportService{domainRepo: domainRepo}
+}
+
+// WithOrg: Returns a copy of the service that only sees the domains of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IExportService): Scoped service
+func (s *ExportService) WithOrg(orgID int64) interfaces.IExportService {
+	return &ExportService{domainRepo: s.domainRepo.WithOrg(orgID)}
+}
+
+// ValidateExport: Checks the format and level of an export before the response starts
+// Params:
+// (format): models.ExportCSV or models.ExportNDJSON
+// (level): models.ExportDomainLevel or models.ExportServerLevel
+// Return:
+// (error): Error if the format or the level is unknown
+func (s *ExportService) ValidateExport(format string, level string) error {
+	if format != models.ExportCSV && format != models.ExportNDJSON {
+		return errors.New("Invalid format, use csv or ndjson")
+	}
+	if level != models.ExportDomainLevel && level != models.ExportServerLevel {
+		return errors.New("Invalid level, use domain or server")
+	}
+	return nil
+}
+
+// WriteDomains: Writes the tracked domains, one row per domain or per server, while they are read from the database
+// Params:
+// (writer): Destination of the export
+// (format): models.ExportCSV or models.ExportNDJSON
+// (level): models.ExportDomainLevel or models.ExportServerLevel
+// Return:
+// (error): Error if the process fails
+func (s *ExportService) WriteDomains(writer io.Writer, format string, level string) error {
+	if err := s.ValidateExport(format, level); err != nil {
+		return err
+	}
+	var write func(row interface{}, record []string) error
+	if format == models.ExportCSV {
+		csvWriter := csv.NewWriter(writer)
+		defer csvWriter.Flush()
+		header := domainExportHeader
+		if level == models.ExportServerLevel {
+			header = serverExportHeader
+		}
+		if err := csvWriter.Write(header); err != nil {
+			return err
+		}
+		write = func(row interface{}, record []string) error {
+			return csvWriter.Write(record)
+		}
+	} else {
+		encoder := json.NewEncoder(writer)
+		write = func(row interface{}, record []string) error {
+			return encoder.Encode(row)
+		}
+	}
+	return s.domainRepo.ForEach(func(domain *models.Domain) error {
+		updatedAt := time.Unix(domain.UpdatedAt, 0).UTC().Format(time.RFC3339)
+		domainRecord := []string{domain.Url, string(domain.SslGrade), string(domain.PreviousSslGrade), strconv.FormatBool(domain.IsDown), strconv.FormatBool(domain.ServersChanged), updatedAt}
+		if level == models.ExportDomainLevel {
+			servers := domain.Servers
+			if servers == nil {
+				servers = []models.Server{}
+			}
+			row := &models.DomainExport{Url: domain.Url, SslGrade: domain.SslGrade, PreviousSslGrade: domain.PreviousSslGrade, IsDown: domain.IsDown, ServersChanged: domain.ServersChanged, UpdatedAt: updatedAt, Servers: servers}
+			return write(row, append(domainRecord, strconv.Itoa(len(servers))))
+		}
+		servers := domain.Servers
+		if len(servers) == 0 {
+			servers = []models.Server{{}}
+		}
+		for _, server := range servers {
+			row := &models.ServerExport{Url: domain.Url, SslGrade: domain.SslGrade, PreviousSslGrade: domain.PreviousSslGrade, IsDown: domain.IsDown, ServersChanged: domain.ServersChanged, UpdatedAt: updatedAt, Address: server.Address, ServerSslGrade: server.SslGrade, Country: server.Country, Owner: server.Owner}
+			record := append(append([]string{}, domainRecord...), server.Address, string(server.SslGrade), server.Country, server.Owner)
+			if err := write(row, record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// minHstsMaxAge: Minimum max-age, in seconds, accepted for the Strict-Transport-Security header (180 days)
+const minHstsMaxAge = 15552000
+
+// hstsMaxAgeRegex: Regex used to read the max-age directive of the Strict-Transport-Security header
+var hstsMaxAgeRegex = regexp.MustCompile(`(?i)max-age\s*=\s*"?(\d+)`)
+
+// AuditHeaders: Evaluates the security headers of a response and computes an overall grade.
+// Each header scores 2 points if it passes, 1 if it has a warning and 0 if it fails
+// Params:
+// (headers): Headers of the home page response
+// Return:
+// (*models.HeadersAudit): Reference to the audit
+func AuditHeaders(headers http.Header) *models.HeadersAudit {
+	results := []models.HeaderResult{
+		auditHsts(headers.Get("Strict-Transport-Security")),
+		auditCsp(headers.Get("Content-Security-Policy"), headers.Get("Content-Security-Policy-Report-Only")),
+		auditFrameOptions(headers.Get("X-Frame-Options"), headers.Get("Content-Security-Policy")),
+		auditContentTypeOptions(headers.Get("X-Content-Type-Options")),
+		auditReferrerPolicy(headers.Get("Referrer-Policy")),
+		auditPermissionsPolicy(headers.Get("Permissions-Policy"), headers.Get("Feature-Policy")),
+	}
+	points := 0
+	for _, result := range results {
+		switch result.Status {
+		case models.HeaderPass:
+			points += 2
+		case models.HeaderWarn:
+			points++
+		}
+	}
+	score := points * 100 / (len(results) * 2)
+	return &models.HeadersAudit{Grade: headersGrade(score), Score: score, CheckedAt: time.Now().Unix(), Headers: results}
+}
+
+// headersGrade: Auxiliary function that converts a headers score into a grade
+// Params:
+// (score): Score between 0 and 100
+// Return:
+// (models.Grade): Grade between A and F
+func headersGrade(score int) models.Grade {
+	switch {
+	case score >= 90:
+		return models.GradeA
+	case score >= 75:
+		return models.GradeB
+	case score >= 60:
+		return models.GradeC
+	case score >= 40:
+		return models.GradeD
+	case score >= 20:
+		return models.GradeE
+	}
+	return models.GradeF
+}
+
+// auditHsts: Auxiliary function that checks that HSTS is enabled with a long enough max-age
+func auditHsts(value string) models.HeaderResult {
+	result := models.HeaderResult{Name: "Strict-Transport-Security", Value: value}
+	if value == "" {
+		return withStatus(result, models.HeaderFail, "Header is missing")
+	}
+	matches := hstsMaxAgeRegex.FindStringSubmatch(value)
+	if len(matches) < 2 {
+		return withStatus(result, models.HeaderFail, "max-age directive is missing")
+	}
+	maxAge, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil || maxAge < minHstsMaxAge {
+		return withStatus(result, models.HeaderWarn, "max-age is shorter than 180 days")
+	}
+	return withStatus(result, models.HeaderPass, "HSTS is enabled")
+}
+
+// auditCsp: Auxiliary function that checks that a Content-Security-Policy is enforced without unsafe sources
+func auditCsp(value string, reportOnly string) models.HeaderResult {
+	result := models.HeaderResult{Name: "Content-Security-Policy", Value: value}
+	if value == "" {
+		if reportOnly != "" {
+			result.Value = reportOnly
+			return withStatus(result, models.HeaderWarn, "Policy is only reported, not enforced")
+		}
+		return withStatus(result, models.HeaderFail, "Header is missing")
+	}
+	lowerValue := strings.ToLower(value)
+	if strings.Contains(lowerValue, "'unsafe-inline'") || strings.Contains(lowerValue, "'unsafe-eval'") {
+		return withStatus(result, models.HeaderWarn, "Policy allows unsafe-inline or unsafe-eval")
+	}
+	return withStatus(result, models.HeaderPass, "Policy is enforced")
+}
+
+// auditFrameOptions: Auxiliary function that checks the clickjacking protection, which a CSP frame-ancestors directive also provides
+func auditFrameOptions(value string, csp string) models.HeaderResult {
+	result := models.HeaderResult{Name: "X-Frame-Options", Value: value}
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "DENY", "SAMEORIGIN":
+		return withStatus(result, models.HeaderPass, "Framing is restricted")
+	case "":
+		if strings.Contains(strings.ToLower(csp), "frame-ancestors") {
+			return withStatus(result, models.HeaderPass, "Framing is restricted by the CSP frame-ancestors directive")
+		}
+		return withStatus(result, models.HeaderFail, "Header is missing")
+	}
+	return withStatus(result, models.HeaderWarn, "Value is deprecated or invalid")
+}
+
+// auditContentTypeOptions: Auxiliary function that checks that MIME sniffing is disabled
+func auditContentTypeOptions(value string) models.HeaderResult {
+	result := models.HeaderResult{Name: "X-Content-Type-Options", Value: value}
+	if strings.EqualFold(strings.TrimSpace(value), "nosniff") {
+		return withStatus(result, models.HeaderPass, "MIME sniffing is disabled")
+	}
+	if value == "" {
+		return withStatus(result, models.HeaderFail, "Header is missing")
+	}
+	return withStatus(result, models.HeaderFail, "Value must be nosniff")
+}
+
+// auditReferrerPolicy: Auxiliary function that checks that the referrer is not leaked to other origins
+func auditReferrerPolicy(value string) models.HeaderResult {
+	result := models.HeaderResult{Name: "Referrer-Policy", Value: value}
+	if value == "" {
+		return withStatus(result, models.HeaderFail, "Header is missing")
+	}
+	policies := strings.Split(value, ",")
+	switch strings.ToLower(strings.TrimSpace(policies[len(policies)-1])) {
+	case "no-referrer", "same-origin", "strict-origin", "strict-origin-when-cross-origin":
+		return withStatus(result, models.HeaderPass, "Referrer is protected")
+	}
+	return withStatus(result, models.HeaderWarn, "Policy can leak the referrer to other origins")
+}
+
+// auditPermissionsPolicy: Auxiliary function that checks that the browser features are restricted
+func auditPermissionsPolicy(value string, featurePolicy string) models.HeaderResult {
+	result := models.HeaderResult{Name: "Permissions-Policy", Value: value}
+	if value != "" {
+		return withStatus(result, models.HeaderPass, "Browser features are restricted")
+	}
+	if featurePolicy != "" {
+		result.Value = featurePolicy
+		return withStatus(result, models.HeaderWarn, "Only the deprecated Feature-Policy header is set")
+	}
+	return withStatus(result, models.HeaderFail, "Header is missing")
+}
+
+// withStatus: Auxiliary function that sets the status and message of a header result
+func withStatus(result models.HeaderResult, status string, message string) models.HeaderResult {
+	result.Status = status
+	result.Message = message
+	return result
+}
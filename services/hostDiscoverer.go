@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// HostDiscoverer: Structure used to store the discovery switch, the repository of the discovered hosts and the logger
+type HostDiscoverer struct {
+	discoveryRepo interfaces.IDiscoveryRepository
+	enabled       bool
+	logger        interfaces.ILogger
+}
+
+// NewHostDiscoverer: Receives a reference to the discoveryRepo and logger interfaces and stores them in the HostDiscoverer structure
+// Params:
+// (discoveryRepo): Reference to a discoveryRepo interface
+// (enabled): True to collect the hosts in each scan. The discovery is opt-in
+// (logger): Reference to the logger of the names that cannot be stored
+// Return:
+// (*HostDiscoverer): Reference to the HostDiscoverer object
+func NewHostDiscoverer(discoveryRepo interfaces.IDiscoveryRepository, enabled bool, logger interfaces.ILogger) *HostDiscoverer {
+	return &HostDiscoverer{discoveryRepo: discoveryRepo, enabled: enabled, logger: logger}
+}
+
+// Discover: Stores as candidates the names of the leaf certificates of a scanned domain.
+// A failure is logged and does not fail the scan
+// Params:
+// (domain): Reference to the stored domain
+func (d *HostDiscoverer) Discover(domain *models.Domain) {
+	if d.enabled == false {
+		return
+	}
+	names := certificateNames(domain)
+	if len(names) == 0 {
+		return
+	}
+	if err := d.discoveryRepo.SaveNames(domain.Id, names, time.Now().Unix()); err != nil {
+		d.logger.Warn(context.Background(), "Discovered hosts cannot be stored", "host", domain.Url, "error", err)
+	}
+}
+
+// certificateNames: Auxiliary function that lists the sibling hosts named by the leaf certificates of a domain.
+// Wildcards, the domain itself and names of other registrable domains, such as the customers of a shared CDN certificate, are left out
+// Params:
+// (domain): Reference to the domain
+// Return:
+// ([]string): Sorted names without duplicates
+func certificateNames(domain *models.Domain) []string {
+	host := strings.ToLower(hostname(domain.Url))
+	parent, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return []string{}
+	}
+	found := make(map[string]bool)
+	for _, endpoint := range domain.Endpoints {
+		if endpoint.Certificate == nil {
+			continue
+		}
+		sanNames := make([]string, 0, len(endpoint.Certificate.CommonNames)+len(endpoint.Certificate.AltNames))
+		sanNames = append(sanNames, endpoint.Certificate.CommonNames...)
+		sanNames = append(sanNames, endpoint.Certificate.AltNames...)
+		for _, name := range sanNames {
+			name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+			if name == "" || name == host || strings.Contains(name, "*") {
+				continue
+			}
+			if nameParent, parentErr := publicsuffix.EffectiveTLDPlusOne(name); parentErr != nil || nameParent != parent {
+				continue
+			}
+			found[name] = true
+		}
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
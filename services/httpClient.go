@@ -0,0 +1,31 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// postJSON: Auxiliary function that posts a JSON payload and checks that the response is 2xx
+// Params:
+// (client): Reference to the http client
+// (url): Destination url
+// (payload): Object to be encoded as the body
+// Return:
+// (error): Error if the process fails
+func postJSON(client *http.Client, url string, payload interface{}) error {
+	body, jsonError := json.Marshal(payload)
+	if jsonError != nil {
+		return jsonError
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval: Time after which the keys loaded from an url are fetched again
+const jwksRefreshInterval = time.Hour
+
+// jwksMissRefreshInterval: Minimum time between two fetches caused by an unknown key id, so
+// tokens signed with made up key ids cannot flood the identity provider
+const jwksMissRefreshInterval = time.Minute
+
+// jsonWebKey: Key of a JWKS document. Only the RSA and EC public keys are read
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JwksSource: Structure used to store the public keys of the identity provider and where they are loaded from
+type JwksSource struct {
+	location   string
+	client     *http.Client
+	mutex      sync.Mutex
+	keys       map[string]crypto.PublicKey
+	fetchedAt  time.Time
+	refreshing bool
+}
+
+// NewJwksSource: Loads the JWKS document and stores its keys in the JwksSource structure
+// Params:
+// (location): Url of the JWKS document, or path of a local file with it
+// Return:
+// (*JwksSource): Reference to the JwksSource object
+// (error): Error if the document cannot be loaded
+func NewJwksSource(location string) (*JwksSource, error) {
+	source := &JwksSource{location: location, client: &http.Client{Timeout: 10 * time.Second}}
+	keys, err := source.load()
+	if err != nil {
+		return nil, err
+	}
+	source.keys, source.fetchedAt = keys, time.Now()
+	return source, nil
+}
+
+// Key: Returns the public key with the given id. The keys of an url are fetched again, in background, when the last
+// fetch is older than jwksRefreshInterval, and right away when the id is unknown and the last fetch is older than
+// jwksMissRefreshInterval, so rotated keys are picked up. Both are counted from the last attempt, failed or not, and
+// only one fetch runs at a time, without holding the lock, so an unreachable identity provider does not stall every request
+// Params:
+// (kid): Id of the key, from the header of the token. If empty, the document must have a single key
+// Return:
+// (crypto.PublicKey): Public key, *rsa.PublicKey or *ecdsa.PublicKey
+// (error): Error if the key is unknown
+func (s *JwksSource) Key(kid string) (crypto.PublicKey, error) {
+	s.mutex.Lock()
+	key, err := s.find(kid)
+	refresh := false
+	if s.isRemote() && s.refreshing == false {
+		age := time.Since(s.fetchedAt)
+		refresh = age >= jwksRefreshInterval || (err != nil && age >= jwksMissRefreshInterval)
+	}
+	if refresh {
+		s.refreshing, s.fetchedAt = true, time.Now()
+	}
+	s.mutex.Unlock()
+	if refresh == false {
+		return key, err
+	}
+	if err == nil {
+		go s.refresh()
+		return key, nil
+	}
+	if refreshErr := s.refresh(); refreshErr == nil {
+		s.mutex.Lock()
+		key, err = s.find(kid)
+		s.mutex.Unlock()
+	}
+	return key, err
+}
+
+// refresh: Loads the JWKS document again and replaces the keys if it succeeds. Must be called by the
+// goroutine that set the refreshing flag, which it clears
+// Return:
+// (error): Error if the document cannot be loaded
+func (s *JwksSource) refresh() error {
+	keys, err := s.load()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.refreshing = false
+	if err == nil {
+		s.keys = keys
+	}
+	return err
+}
+
+// find: Searchs for a key among the loaded ones
+// Params:
+// (kid): Id of the key, empty to take the only key
+// Return:
+// (crypto.PublicKey): Public key
+// (error): Error if the key is unknown
+func (s *JwksSource) find(kid string) (crypto.PublicKey, error) {
+	if kid == "" {
+		if len(s.keys) == 1 {
+			for _, key := range s.keys {
+				return key, nil
+			}
+		}
+		return nil, errors.New("Token has no key id")
+	}
+	key, ok := s.keys[kid]
+	if ok == false {
+		return nil, fmt.Errorf("Unknown key %s", kid)
+	}
+	return key, nil
+}
+
+// isRemote: Checks if the keys are loaded from an url
+// Return:
+// (bool): True if the location is an url. False if it is a file
+func (s *JwksSource) isRemote() bool {
+	return strings.HasPrefix(s.location, "https://") || strings.HasPrefix(s.location, "http://")
+}
+
+// load: Reads the JWKS document and returns its keys. It does not touch the state of the source, so it runs without the lock
+// Return:
+// (map[string]crypto.PublicKey): Keys by id
+// (error): Error if the document cannot be read or has no usable key
+func (s *JwksSource) load() (map[string]crypto.PublicKey, error) {
+	var content []byte
+	var err error
+	if s.isRemote() {
+		content, err = s.fetch()
+	} else {
+		content, err = ioutil.ReadFile(s.location)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err = json.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, keyErr := parseJsonWebKey(jwk)
+		if keyErr != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no usable signing key")
+	}
+	return keys, nil
+}
+
+// fetch: Downloads the JWKS document
+// Return:
+// ([]byte): Content of the document
+// (error): Error if the request fails
+func (s *JwksSource) fetch() ([]byte, error) {
+	resp, err := s.client.Get(s.location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("Unexpected status code %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parseJsonWebKey: Auxiliary function that converts a JSON web key into a public key
+// Params:
+// (jwk): JSON web key
+// Return:
+// (crypto.PublicKey): Public key, *rsa.PublicKey or *ecdsa.PublicKey
+// (error): Error if the key type or curve is not supported or the key is malformed
+func parseJsonWebKey(jwk jsonWebKey) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, nErr := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, eErr := base64.RawURLEncoding.DecodeString(jwk.E)
+		if nErr != nil || eErr != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("Malformed RSA key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("Unsupported curve %s", jwk.Crv)
+		}
+		x, xErr := base64.RawURLEncoding.DecodeString(jwk.X)
+		y, yErr := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if xErr != nil || yErr != nil {
+			return nil, errors.New("Malformed EC key")
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if curve.IsOnCurve(key.X, key.Y) == false {
+			return nil, errors.New("Malformed EC key")
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("Unsupported key type %s", jwk.Kty)
+}
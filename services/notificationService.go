@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// NotificationService: Structure used to store the notificationService functions
+type NotificationService struct {
+	channelRepo interfaces.IChannelRepository
+	notifiers   map[string]interfaces.INotifier
+	logger      interfaces.ILogger
+}
+
+// NewNotificationService: Receives a reference to the channelRepo interface, the notifiers by channel type and the logger and stores them in the NotificationService structure
+// Params:
+// (channelRepo): Reference to a channelRepo interface
+// (notifiers): Notifier used for each channel type. Types without notifier cannot be used
+// (logger): Reference to the logger of the failed deliveries
+// Return:
+// (*NotificationService): Reference to the NotificationService object
+func NewNotificationService(channelRepo interfaces.IChannelRepository, notifiers map[string]interfaces.INotifier, logger interfaces.ILogger) *NotificationService {
+	return &NotificationService{channelRepo: channelRepo, notifiers: notifiers, logger: logger}
+}
+
+// WithOrg: Returns a copy of the service that only sees the channels of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.INotificationService): Scoped service
+func (s *NotificationService) WithOrg(orgID int64) interfaces.INotificationService {
+	return &NotificationService{channelRepo: s.channelRepo.WithOrg(orgID), notifiers: s.notifiers, logger: s.logger}
+}
+
+// Publish: Sends the event, in background, through every channel of the organization of its domain whose events, hosts and tags match it.
+// The failed deliveries are logged
+// Params:
+// (event): Reference to the event to be sent
+func (s *NotificationService) Publish(event *models.Event) {
+	if event.Domain == nil {
+		return
+	}
+	channels, err := s.channelRepo.WithOrg(event.Domain.OrgId).GetAll()
+	if err != nil {
+		s.logger.Error(context.Background(), "Notification channels cannot be read", "orgId", event.Domain.OrgId, "eventType", event.Type, "error", err)
+		return
+	}
+	for _, channel := range channels {
+		notifier, ok := s.notifiers[channel.Type]
+		if ok == false || matchesEvent(channel.Events, event.Type) == false || matchesHost(channel.Hosts, event.Url) == false || hasTag(domainTags(event.Domain), channel.Tags) == false {
+			continue
+		}
+		go s.notify(notifier, channel, event)
+	}
+}
+
+// notify: Sends the event through a channel and logs the delivery if it fails
+// Params:
+// (notifier): Notifier of the channel type
+// (channel): Reference to the channel
+// (event): Reference to the event
+func (s *NotificationService) notify(notifier interfaces.INotifier, channel *models.Channel, event *models.Event) {
+	if err := notifier.Notify(channel.Target, event); err != nil {
+		s.logger.Error(context.Background(), "Notification cannot be sent", "channelId", channel.Id, "channelType", channel.Type, "eventType", event.Type, "url", event.Url, "error", err)
+	}
+}
+
+// CreateChannel: Validates and stores a new notification channel
+// Params:
+// (body): JSON body with the name, type, target, events, hosts and tags of the channel
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *NotificationService) CreateChannel(body []byte) ([]byte, error) {
+	var channel *models.Channel
+	if err := json.Unmarshal(body, &channel); err != nil || channel == nil {
+		return nil, errors.New("Invalid body")
+	}
+	if _, ok := s.notifiers[channel.Type]; ok == false {
+		return nil, fmt.Errorf("Channel type %s is not available", channel.Type)
+	}
+	if strings.TrimSpace(channel.Target) == "" {
+		return nil, errors.New("Target is required")
+	}
+	if channel.Events == nil {
+		channel.Events = []string{}
+	}
+	for _, eventType := range channel.Events {
+		if isEventType(eventType) == false {
+			return nil, fmt.Errorf("Unknown event %s", eventType)
+		}
+	}
+	if channel.Hosts == nil {
+		channel.Hosts = []string{}
+	}
+	for _, pattern := range channel.Hosts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("Invalid host pattern %s", pattern)
+		}
+	}
+	tags, tagsErr := normalizeTags(channel.Tags)
+	if tagsErr != nil {
+		return nil, tagsErr
+	}
+	channel.Tags = tags
+	channel.CreatedAt = time.Now().Unix()
+	id, saveErr := s.channelRepo.Save(channel)
+	if saveErr != nil {
+		return nil, saveErr
+	}
+	channel.Id = id
+	jsonBody, jsonError := json.Marshal(channel)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// GetChannels: Returns a JSON object with the notification channels
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *NotificationService) GetChannels() ([]byte, error) {
+	channels, err := s.channelRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": channels})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// DeleteChannel: Removes a notification channel
+// Params:
+// (ID): Id of the channel
+// Return:
+// (error): Error if the process fails
+func (s *NotificationService) DeleteChannel(ID int64) error {
+	if _, err := s.channelRepo.FindByID(ID); err != nil {
+		return errors.New("Channel not found")
+	}
+	return s.channelRepo.Delete(ID)
+}
+
+// matchesHost: Auxiliary function that checks if a host matches one of the patterns, such as *.example.com.
+// An empty pattern list matches every host
+// Params:
+// (patterns): Host patterns
+// (host): Host to be checked
+// Return:
+// (bool): True if the host matches. False if not
+func matchesHost(patterns []string, host string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, host); matched {
+			return true
+		}
+	}
+	return false
+}
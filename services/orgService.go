@@ -0,0 +1,91 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// OrgService: Structure used to store the orgService functions and the ids of the organizations known to exist
+type OrgService struct {
+	orgRepo interfaces.IOrgRepository
+	mutex   sync.RWMutex
+	known   map[int64]bool
+}
+
+// NewOrgService: Receives a reference to the orgRepo interface and stores it in the OrgService structure
+// Params:
+// (orgRepo): Reference to an orgRepo interface
+// Return:
+// (*OrgService): Reference to the OrgService object
+func NewOrgService(orgRepo interfaces.IOrgRepository) *OrgService {
+	return &OrgService{orgRepo: orgRepo, known: make(map[int64]bool)}
+}
+
+// GetOrg: Returns a JSON object with an organization
+// Params:
+// (ID): Id of the organization
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *OrgService) GetOrg(ID int64) ([]byte, error) {
+	org, err := s.orgRepo.FindByID(ID)
+	if err != nil {
+		return nil, errors.New("Organization not found")
+	}
+	jsonBody, jsonError := json.Marshal(org)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// CreateOrg: Stores a new organization
+// Params:
+// (name): Name of the organization
+// Return:
+// (*models.Org): Reference to the stored organization
+// (error): Error if the name is empty or the process fails
+func (s *OrgService) CreateOrg(name string) (*models.Org, error) {
+	org := &models.Org{Name: strings.TrimSpace(name), CreatedAt: time.Now().Unix()}
+	if org.Name == "" {
+		return nil, errors.New("Name is required")
+	}
+	id, err := s.orgRepo.Save(org)
+	if err != nil {
+		return nil, err
+	}
+	org.Id = id
+	return org, nil
+}
+
+// CheckOrg: Checks that an organization exists. The organizations are never deleted, so the ids found are
+// remembered and the credentials of a known organization do not query the database on every request
+// Params:
+// (ID): Id of the organization
+// Return:
+// (error): Error if the organization does not exist
+func (s *OrgService) CheckOrg(ID int64) error {
+	s.mutex.RLock()
+	known := s.known[ID]
+	s.mutex.RUnlock()
+	if known {
+		return nil
+	}
+	if ID <= 0 {
+		return errors.New("Unknown organization")
+	}
+	if _, err := s.orgRepo.FindByID(ID); err != nil {
+		return errors.New("Unknown organization")
+	}
+	s.mutex.Lock()
+	s.known[ID] = true
+	s.mutex.Unlock()
+	return nil
+}
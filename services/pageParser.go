@@ -0,0 +1,84 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// pageClient: Client used to fetch the home page of the domains
+var pageClient = &http.Client{Timeout: 10 * time.Second}
+
+// parsePage: Auxiliary function that reads the icon and title of an HTML page.
+// The og:title meta has priority over the title tag, and the icon defaults to /favicon.ico
+// Params:
+// (pageUrl): Final url of the page, used to resolve relative icons
+// (body): Body of the response
+// (contentType): Content-Type header of the response, used to decode the body
+// Return:
+// (logo): Absolute url of the page icon
+// (title): Page title
+func parsePage(pageUrl *url.URL, body io.Reader, contentType string) (logo string, title string) {
+	logo = (&url.URL{Scheme: pageUrl.Scheme, Host: pageUrl.Host, Path: "/favicon.ico"}).String()
+	reader, err := charset.NewReader(body, contentType)
+	if err != nil {
+		return logo, ""
+	}
+	ogTitle := ""
+	tokenizer := html.NewTokenizer(reader)
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+		token := tokenizer.Token()
+		switch token.Data {
+		case "title":
+			if title == "" && tokenizer.Next() == html.TextToken {
+				title = strings.TrimSpace(tokenizer.Token().Data)
+			}
+		case "link":
+			if strings.Contains(strings.ToLower(attribute(token, "rel")), "icon") && attribute(token, "href") != "" {
+				if iconUrl, parseErr := pageUrl.Parse(attribute(token, "href")); parseErr == nil {
+					logo = iconUrl.String()
+				}
+			}
+		case "meta":
+			if strings.ToLower(attribute(token, "property")) == "og:title" {
+				ogTitle = attribute(token, "content")
+			}
+		case "body":
+			if ogTitle != "" {
+				return logo, ogTitle
+			}
+			return logo, title
+		}
+	}
+	if ogTitle != "" {
+		return logo, ogTitle
+	}
+	return logo, title
+}
+
+// attribute: Auxiliary function that returns the value of an attribute of an HTML token
+// Params:
+// (token): HTML token
+// (key): Attribute name
+// Return:
+// (string): Attribute value, empty if the token does not have it
+func attribute(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if strings.ToLower(attr.Key) == key {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
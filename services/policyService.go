@@ -0,0 +1,288 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// PolicyService: Structure used to store the policyService functions
+type PolicyService struct {
+	policyRepo interfaces.IPolicyRepository
+	domainRepo interfaces.IDomainRepository
+}
+
+// NewPolicyService: Receives a reference to the policyRepo and domainRepo interfaces and stores them in the PolicyService structure
+// Params:
+// (policyRepo): Reference to a policyRepo interface
+// (domainRepo): Reference to a domainRepo interface
+// Return:
+// (*PolicyService): Reference to the PolicyService object
+func NewPolicyService(policyRepo interfaces.IPolicyRepository, domainRepo interfaces.IDomainRepository) *PolicyService {
+	return &PolicyService{policyRepo: policyRepo, domainRepo: domainRepo}
+}
+
+// WithOrg: Returns a copy of the service that only sees the policies and domains of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IPolicyService): Scoped service
+func (s *PolicyService) WithOrg(orgID int64) interfaces.IPolicyService {
+	return &PolicyService{policyRepo: s.policyRepo.WithOrg(orgID), domainRepo: s.domainRepo.WithOrg(orgID)}
+}
+
+// Evaluate: Checks the domain against every policy of its organization
+// Params:
+// (domain): Reference to the domain to be checked
+// Return:
+// (*models.Compliance): Reference to the compliance block, nil if the policies cannot be loaded
+func (s *PolicyService) Evaluate(domain *models.Domain) *models.Compliance {
+	policies, err := s.policyRepo.WithOrg(domain.OrgId).GetAll()
+	if err != nil {
+		return nil
+	}
+	return evaluatePolicies(policies, domain, time.Now())
+}
+
+// evaluatePolicies: Auxiliary function that checks a domain against the policies whose tags scope it
+// Params:
+// (policies): Policy slice
+// (domain): Reference to the domain
+// (now): Moment of the evaluation
+// Return:
+// (*models.Compliance): Reference to the compliance block
+func evaluatePolicies(policies []*models.Policy, domain *models.Domain, now time.Time) *models.Compliance {
+	compliance := &models.Compliance{Compliant: true, EvaluatedAt: now.Unix(), Results: []models.RuleResult{}}
+	for _, policy := range policies {
+		if hasTag(domain.Tags, policy.Tags) == false {
+			continue
+		}
+		result := evaluatePolicy(policy, domain, now)
+		if result.Passed == false {
+			compliance.Compliant = false
+		}
+		compliance.Results = append(compliance.Results, result)
+	}
+	return compliance
+}
+
+// evaluatePolicy: Auxiliary function that checks a domain against a single policy
+// Params:
+// (policy): Reference to the policy
+// (domain): Reference to the domain
+// (now): Moment of the evaluation
+// Return:
+// (models.RuleResult): Result of the rule, with the reasons of the failure
+func evaluatePolicy(policy *models.Policy, domain *models.Domain, now time.Time) models.RuleResult {
+	reasons := make([]string, 0)
+	switch policy.Type {
+	case models.PolicyMinGrade:
+		if len(domain.Servers) == 0 {
+			reasons = append(reasons, "domain has no servers")
+		}
+		for _, server := range domain.Servers {
+			if server.SslGrade.IsGraded() == false {
+				reasons = append(reasons, fmt.Sprintf("server %s is not graded", server.Address))
+			} else if server.SslGrade.Compare(policy.MinGrade) < 0 {
+				reasons = append(reasons, fmt.Sprintf("server %s has grade %s, below %s", server.Address, server.SslGrade, policy.MinGrade))
+			}
+		}
+	case models.PolicyForbiddenProtocols:
+		for _, endpoint := range domain.Endpoints {
+			if endpoint.Details == nil {
+				reasons = append(reasons, fmt.Sprintf("server %s has no protocol information", endpoint.IpAddress))
+				continue
+			}
+			for _, protocol := range endpoint.Details.Protocols {
+				name := fmt.Sprintf("%s %s", protocol.Name, protocol.Version)
+				for _, forbidden := range policy.Protocols {
+					if strings.EqualFold(name, forbidden) {
+						reasons = append(reasons, fmt.Sprintf("server %s supports %s", endpoint.IpAddress, name))
+					}
+				}
+			}
+		}
+	case models.PolicyCertificateMinDays:
+		limit := now.Add(time.Duration(policy.Days) * 24 * time.Hour)
+		for _, endpoint := range domain.Endpoints {
+			if endpoint.Certificate == nil {
+				reasons = append(reasons, fmt.Sprintf("server %s has no certificate information", endpoint.IpAddress))
+				continue
+			}
+			notAfter := time.Unix(0, endpoint.Certificate.NotAfter*int64(time.Millisecond))
+			if notAfter.Before(limit) {
+				reasons = append(reasons, fmt.Sprintf("certificate of server %s expires on %s", endpoint.IpAddress, notAfter.UTC().Format("2006-01-02")))
+			}
+		}
+	case models.PolicyUniformGrade:
+		for _, server := range domain.Servers {
+			if server.SslGrade != domain.Servers[0].SslGrade {
+				reasons = append(reasons, fmt.Sprintf("server %s has grade %s, server %s has grade %s", domain.Servers[0].Address, domain.Servers[0].SslGrade, server.Address, server.SslGrade))
+			}
+		}
+	default:
+		reasons = append(reasons, fmt.Sprintf("unknown policy type %s", policy.Type))
+	}
+	return models.RuleResult{Policy: policy.Name, Type: policy.Type, Passed: len(reasons) == 0, Reasons: reasons}
+}
+
+// validatePolicy: Auxiliary function that checks that a policy has the parameters its type needs
+// Params:
+// (policy): Reference to the policy
+// Return:
+// (error): Error if the policy is invalid
+func validatePolicy(policy *models.Policy) error {
+	if strings.TrimSpace(policy.Name) == "" {
+		return errors.New("Policy name is required")
+	}
+	switch policy.Type {
+	case models.PolicyMinGrade:
+		if policy.MinGrade.IsGraded() == false {
+			return fmt.Errorf("Policy %s needs a min_grade", policy.Name)
+		}
+	case models.PolicyForbiddenProtocols:
+		if len(policy.Protocols) == 0 {
+			return fmt.Errorf("Policy %s needs protocols", policy.Name)
+		}
+	case models.PolicyCertificateMinDays:
+		if policy.Days <= 0 {
+			return fmt.Errorf("Policy %s needs a positive days value", policy.Name)
+		}
+	case models.PolicyUniformGrade:
+	default:
+		return fmt.Errorf("Unknown policy type %s", policy.Type)
+	}
+	if policy.Protocols == nil {
+		policy.Protocols = []string{}
+	}
+	tags, err := normalizeTags(policy.Tags)
+	if err != nil {
+		return fmt.Errorf("Policy %s: %s", policy.Name, err.Error())
+	}
+	policy.Tags = tags
+	return nil
+}
+
+// LoadFile: Reads the policies from a YAML file and replaces the stored ones with them
+// Params:
+// (path): Path of the YAML file
+// Return:
+// (error): Error if the process fails
+func (s *PolicyService) LoadFile(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var policyFile models.PolicyFile
+	if err = yaml.UnmarshalStrict(content, &policyFile); err != nil {
+		return err
+	}
+	for _, policy := range policyFile.Policies {
+		if policy.MinGrade, err = models.ParseGrade(string(policy.MinGrade)); err != nil {
+			return err
+		}
+		if err = validatePolicy(policy); err != nil {
+			return err
+		}
+	}
+	return s.policyRepo.ReplaceAll(policyFile.Policies)
+}
+
+// CreatePolicy: Validates and stores a new policy
+// Params:
+// (body): JSON body with the name, type and parameters of the policy
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *PolicyService) CreatePolicy(body []byte) ([]byte, error) {
+	var policy *models.Policy
+	if err := json.Unmarshal(body, &policy); err != nil || policy == nil {
+		return nil, errors.New("Invalid body")
+	}
+	if err := validatePolicy(policy); err != nil {
+		return nil, err
+	}
+	id, saveErr := s.policyRepo.Save(policy)
+	if saveErr != nil {
+		return nil, saveErr
+	}
+	policy.Id = id
+	jsonBody, jsonError := json.Marshal(policy)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// GetPolicies: Returns a JSON object with the policies
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *PolicyService) GetPolicies() ([]byte, error) {
+	policies, err := s.policyRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": policies})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// DeletePolicy: Removes a policy
+// Params:
+// (ID): Id of the policy
+// Return:
+// (error): Error if the process fails
+func (s *PolicyService) DeletePolicy(ID int64) error {
+	if _, err := s.policyRepo.FindByID(ID); err != nil {
+		return errors.New("Policy not found")
+	}
+	return s.policyRepo.Delete(ID)
+}
+
+// GetComplianceSummary: Evaluates every tracked domain against the current policies and returns a JSON object with the violators
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *PolicyService) GetComplianceSummary() ([]byte, error) {
+	policies, err := s.policyRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	domains, err := s.domainRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	summary := &models.ComplianceSummary{Domains: len(domains), Violators: []*models.Violation{}}
+	for _, domain := range domains {
+		compliance := evaluatePolicies(policies, domain, now)
+		if compliance.Compliant {
+			summary.Compliant++
+			continue
+		}
+		summary.Violating++
+		violation := &models.Violation{Url: domain.Url, Failed: []models.RuleResult{}}
+		for _, result := range compliance.Results {
+			if result.Passed == false {
+				violation.Failed = append(violation.Failed, result)
+			}
+		}
+		summary.Violators = append(summary.Violators, violation)
+	}
+	jsonBody, jsonError := json.Marshal(summary)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
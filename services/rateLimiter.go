@@ -0,0 +1,93 @@
+package services
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// tokenBucket: Tokens left to a client and the moment they were last counted
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// RateLimiter: Structure used to store a token bucket per client. Each bucket holds up to limit tokens
+// and is refilled at limit tokens per period, so a client can burst up to limit requests and then
+// make one request every period/limit
+type RateLimiter struct {
+	limit   int
+	period  time.Duration
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+	sweptAt time.Time
+	now     func() time.Time
+}
+
+// NewRateLimiter: Receives the budget of each client and stores it in the RateLimiter structure
+// Params:
+// (limit): Number of requests allowed per period
+// (period): Period of the budget
+// Return:
+// (*RateLimiter): Reference to the RateLimiter object
+func NewRateLimiter(limit int, period time.Duration) *RateLimiter {
+	return &RateLimiter{limit: limit, period: period, buckets: make(map[string]*tokenBucket), sweptAt: time.Now(), now: time.Now}
+}
+
+// Take: Refills the bucket of a client and takes a token from it if there is one
+// Params:
+// (key): Key of the client
+// Return:
+// (models.RateLimit): Outcome, with the tokens left and the seconds until the next token and until the bucket is full
+func (l *RateLimiter) Take(key string) models.RateLimit {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := l.now()
+	l.sweep(now)
+	bucket, ok := l.buckets[key]
+	if ok == false {
+		bucket = &tokenBucket{tokens: float64(l.limit), updatedAt: now}
+		l.buckets[key] = bucket
+	}
+	perToken := float64(l.period) / float64(l.limit)
+	bucket.tokens = math.Min(float64(l.limit), bucket.tokens+float64(now.Sub(bucket.updatedAt))/perToken)
+	bucket.updatedAt = now
+	result := models.RateLimit{Limit: l.limit}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = ceilSeconds(time.Duration((1 - bucket.tokens) * perToken))
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = ceilSeconds(time.Duration((float64(l.limit) - bucket.tokens) * perToken))
+	return result
+}
+
+// ceilSeconds: Auxiliary function that rounds a duration up to whole seconds. The duration is truncated to
+// nanoseconds first, so the rounding noise of the token count does not add a second
+// Params:
+// (duration): Duration
+// Return:
+// (int64): Seconds
+func ceilSeconds(duration time.Duration) int64 {
+	return int64((duration + time.Second - 1) / time.Second)
+}
+
+// sweep: Removes, once per period, the buckets that have been refilled completely, since they are
+// the same as a new bucket
+// Params:
+// (now): Current time
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.sweptAt) < l.period {
+		return
+	}
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updatedAt) >= l.period {
+			delete(l.buckets, key)
+		}
+	}
+	l.sweptAt = now
+}
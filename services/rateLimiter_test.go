@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+func TestRateLimiterTake(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	now := start
+	limiter := NewRateLimiter(2, 2*time.Second)
+	limiter.now = func() time.Time { return now }
+	steps := []struct {
+		name    string
+		elapsed time.Duration
+		key     string
+		want    models.RateLimit
+	}{
+		{"first request of the burst", 0, "a", models.RateLimit{Allowed: true, Limit: 2, Remaining: 1, Reset: 1}},
+		{"last request of the burst", 0, "a", models.RateLimit{Allowed: true, Limit: 2, Remaining: 0, Reset: 2}},
+		{"empty bucket", 0, "a", models.RateLimit{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: 1, Reset: 2}},
+		{"other client has its own bucket", 0, "b", models.RateLimit{Allowed: true, Limit: 2, Remaining: 1, Reset: 1}},
+		{"half a token refilled", 500 * time.Millisecond, "a", models.RateLimit{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: 1, Reset: 2}},
+		{"one token refilled", time.Second, "a", models.RateLimit{Allowed: true, Limit: 2, Remaining: 0, Reset: 2}},
+		{"refill is capped at the limit", time.Minute, "a", models.RateLimit{Allowed: true, Limit: 2, Remaining: 1, Reset: 1}},
+	}
+	for _, step := range steps {
+		now = start.Add(step.elapsed)
+		if got := limiter.Take(step.key); got != step.want {
+			t.Errorf("%s: Take(%s) = %+v, want %+v", step.name, step.key, got, step.want)
+		}
+	}
+}
+
+func TestRateLimiterRetryAfterRoundsUp(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	limiter := NewRateLimiter(4, time.Minute)
+	limiter.now = func() time.Time { return now }
+	for ii := 0; ii < 4; ii++ {
+		limiter.Take("a")
+	}
+	now = now.Add(5 * time.Second)
+	got := limiter.Take("a")
+	if got.Allowed || got.RetryAfter != 10 || got.Reset != 55 {
+		t.Errorf("Take = %+v, want a denial with RetryAfter 10 and Reset 55", got)
+	}
+}
+
+func TestRateLimiterSweepsFullBuckets(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	limiter := NewRateLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+	limiter.sweptAt = now
+	limiter.Take("a")
+	now = now.Add(30 * time.Second)
+	limiter.Take("b")
+	now = now.Add(40 * time.Second)
+	limiter.Take("c")
+	if _, ok := limiter.buckets["a"]; ok {
+		t.Error("bucket of a was not swept after a full period")
+	}
+	if _, ok := limiter.buckets["b"]; ok == false {
+		t.Error("bucket of b was swept before it was refilled")
+	}
+}
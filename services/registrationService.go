@@ -0,0 +1,74 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// RegistrationService: Structure used to store the registrationService functions
+type RegistrationService struct {
+	domainRepo interfaces.IDomainRepository
+}
+
+// NewRegistrationService: Receives a reference to the domainRepo interface and stores it in the RegistrationService structure
+// Params:
+// (domainRepo): Reference to a domainRepo interface
+// Return:
+// (*RegistrationService): Reference to the RegistrationService object
+func NewRegistrationService(domainRepo interfaces.IDomainRepository) *RegistrationService {
+	return &RegistrationService{domainRepo: domainRepo}
+}
+
+// WithOrg: Returns a copy of the service that only sees the domains of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IRegistrationService): Scoped service
+func (s *RegistrationService) WithOrg(orgID int64) interfaces.IRegistrationService {
+	return &RegistrationService{domainRepo: s.domainRepo.WithOrg(orgID)}
+}
+
+// GetExpiring: Returns a JSON object with the registrations of the tracked domains that expire within the given days,
+// the closest expiry first. Registrations that already expired are included, and those without an expiry date are left out
+// Params:
+// (days): Size of the window in days
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *RegistrationService) GetExpiring(days int) ([]byte, error) {
+	if days <= 0 {
+		return nil, errors.New("Invalid days")
+	}
+	domains, err := s.domainRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	limit := now.Add(time.Duration(days) * 24 * time.Hour)
+	expiring := make([]*models.ExpiringRegistration, 0)
+	for _, domain := range domains {
+		if domain.Registration == nil || domain.Registration.ExpiresAt == 0 {
+			continue
+		}
+		expiresAt := time.Unix(domain.Registration.ExpiresAt, 0)
+		if expiresAt.After(limit) {
+			continue
+		}
+		daysLeft := int(expiresAt.Sub(now).Hours() / 24)
+		expiring = append(expiring, &models.ExpiringRegistration{Url: domain.Url, DaysLeft: daysLeft, Registration: domain.Registration})
+	}
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiring[i].Registration.ExpiresAt < expiring[j].Registration.ExpiresAt
+	})
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": expiring})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// reportHistoryDays: Days of grade history shown in the report
+const reportHistoryDays = 90
+
+// reportChartLevels: Grades drawn as horizontal lines in the history chart
+var reportChartLevels = []models.Grade{models.GradeAPlus, models.GradeA, models.GradeB, models.GradeC, models.GradeF, models.GradeTrustIssues}
+
+// ReportService: Structure used to store the reportService functions
+type ReportService struct {
+	domainRepo interfaces.IDomainRepository
+}
+
+// reportData: Values rendered by the report template
+type reportData struct {
+	Domain      *models.Domain
+	GeneratedAt int64
+	History     []models.GradePoint
+	HistoryDays int
+	Chart       reportChart
+	Changes     []string
+}
+
+// reportChart: Coordinates of the grade history chart
+type reportChart struct {
+	Width  int
+	Height int
+	Left   int
+	Right  int
+	From   int64
+	To     int64
+	Points string
+	Levels []chartMark
+	Dots   []chartMark
+}
+
+// chartMark: Point or line of the grade history chart
+type chartMark struct {
+	X      int
+	Y      int
+	Label  string
+	IsDown bool
+}
+
+// NewReportService: Receives a reference to the domainRepo interface and stores it in the ReportService structure
+// Params:
+// (domainRepo): Reference to a domainRepo interface
+// Return:
+// (*ReportService): Reference to the ReportService object
+func NewReportService(domainRepo interfaces.IDomainRepository) *ReportService {
+	return &ReportService{domainRepo: domainRepo}
+}
+
+// WithOrg: Returns a copy of the service that only sees the domains of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IReportService): Scoped service
+func (s *ReportService) WithOrg(orgID int64) interfaces.IReportService {
+	return &ReportService{domainRepo: s.domainRepo.WithOrg(orgID)}
+}
+
+// GetReport: Renders the security report of a domain
+// Params:
+// (hostPath): Host of the domain
+// (format): Format of the report. Only html is supported, and it is styled to be saved as PDF from a browser
+// Return:
+// ([]byte): Rendered report
+// (error): Error if the process fails
+func (s *ReportService) GetReport(hostPath string, format string) ([]byte, error) {
+	if format != "html" {
+		return nil, errors.New("Invalid format, use html")
+	}
+	domain, err := s.domainRepo.FindByUrl(hostPath)
+	if err != nil {
+		return nil, errors.New("Domain not found")
+	}
+	now := time.Now()
+	history, err := s.domainRepo.GetGradeHistory(domain.Id, now.Add(-reportHistoryDays*24*time.Hour).Unix())
+	if err != nil {
+		return nil, err
+	}
+	data := &reportData{Domain: domain, GeneratedAt: now.Unix(), History: history, HistoryDays: reportHistoryDays, Chart: gradeChart(history), Changes: detectedChanges(domain)}
+	var buffer bytes.Buffer
+	if err := reportTemplate.Execute(&buffer, data); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// gradeChart: Auxiliary function that places the grade history in a line chart, time on the x axis and grade rank on the y axis
+// Params:
+// (history): Grade points, the oldest first
+// Return:
+// (reportChart): Chart coordinates. Without points if the history is empty
+func gradeChart(history []models.GradePoint) reportChart {
+	chart := reportChart{Width: 640, Height: 220, Left: 30, Right: 630, Levels: []chartMark{}, Dots: []chartMark{}}
+	top, bottom := 10, chart.Height-20
+	y := func(grade models.Grade) int {
+		return bottom - (bottom-top)*grade.Rank()/models.GradeAPlus.Rank()
+	}
+	for _, level := range reportChartLevels {
+		chart.Levels = append(chart.Levels, chartMark{Y: y(level), Label: string(level)})
+	}
+	if len(history) == 0 {
+		return chart
+	}
+	chart.From, chart.To = history[0].ScannedAt, history[len(history)-1].ScannedAt
+	points := make([]string, 0, len(history))
+	for ii, point := range history {
+		x := (chart.Left + chart.Right) / 2
+		if chart.To > chart.From {
+			x = chart.Left + int(int64(chart.Right-chart.Left)*(point.ScannedAt-chart.From)/(chart.To-chart.From))
+		} else if len(history) > 1 {
+			x = chart.Left + (chart.Right-chart.Left)*ii/(len(history)-1)
+		}
+		label := string(point.SslGrade)
+		if point.SslGrade.IsGraded() == false {
+			label = "N/A"
+		}
+		label = fmt.Sprintf("%s: %s", time.Unix(point.ScannedAt, 0).UTC().Format("2006-01-02 15:04"), label)
+		chart.Dots = append(chart.Dots, chartMark{X: x, Y: y(point.SslGrade), Label: label, IsDown: point.IsDown})
+		points = append(points, fmt.Sprintf("%d,%d", x, y(point.SslGrade)))
+	}
+	chart.Points = strings.Join(points, " ")
+	return chart
+}
+
+// detectedChanges: Auxiliary function that describes the changes found in the last scan of a domain
+// Params:
+// (domain): Reference to the domain
+// Return:
+// ([]string): Description of each change
+func detectedChanges(domain *models.Domain) []string {
+	changes := make([]string, 0)
+	if domain.IsDown {
+		changes = append(changes, "The domain could not be reached in the last scan")
+	}
+	if domain.SslGrade != domain.PreviousSslGrade {
+		changes = append(changes, fmt.Sprintf("Grade changed from %s to %s", displayGrade(domain.PreviousSslGrade), displayGrade(domain.SslGrade)))
+	}
+	if domain.ServersChanged {
+		changes = append(changes, "The servers changed since the previous scan")
+	}
+	if domain.Dns != nil {
+		changes = append(changes, domain.Dns.Changes...)
+	}
+	return changes
+}
+
+// displayGrade: Auxiliary function that writes a grade for people, N/A if it is not graded
+// Params:
+// (grade): Grade
+// Return:
+// (string): Grade as text
+func displayGrade(grade models.Grade) string {
+	if grade.IsGraded() == false {
+		return "N/A"
+	}
+	return string(grade)
+}
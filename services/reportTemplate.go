@@ -0,0 +1,170 @@
+package services
+
+import (
+	"html/template"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// reportFuncs: Functions available in the report template
+var reportFuncs = template.FuncMap{
+	"date": func(unix int64) string {
+		if unix == 0 {
+			return "-"
+		}
+		return time.Unix(unix, 0).UTC().Format("2006-01-02 15:04 UTC")
+	},
+	"grade": displayGrade,
+	"gradeClass": func(grade models.Grade) string {
+		switch {
+		case grade.IsGraded() == false:
+			return "grade-none"
+		case grade.Compare(models.GradeAMinus) >= 0:
+			return "grade-good"
+		case grade.Compare(models.GradeC) >= 0:
+			return "grade-fair"
+		}
+		return "grade-bad"
+	},
+}
+
+// reportTemplate: Template of the security report of a domain. It has no scripts nor external resources
+// and its styles target print, so the browser can save it as PDF
+var reportTemplate = template.Must(template.New("report").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>TLS security report - {{.Domain.Url}}</title>
+<style>
+	@page { size: A4; margin: 15mm; }
+	body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 0 auto; max-width: 900px; padding: 24px; font-size: 13px; }
+	h1 { font-size: 22px; margin: 0 0 4px; }
+	h2 { font-size: 16px; border-bottom: 2px solid #ddd; padding-bottom: 4px; margin-top: 28px; }
+	.muted { color: #777; }
+	.summary { display: flex; gap: 16px; margin-top: 16px; }
+	.card { border: 1px solid #ddd; border-radius: 6px; padding: 12px 16px; flex: 1; }
+	.card .value { font-size: 28px; font-weight: bold; }
+	table { width: 100%; border-collapse: collapse; margin-top: 8px; }
+	th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #eee; vertical-align: top; }
+	th { background: #f5f5f5; }
+	tr { page-break-inside: avoid; }
+	.grade-good { color: #2e7d32; }
+	.grade-fair { color: #ef6c00; }
+	.grade-bad { color: #c62828; }
+	.grade-none { color: #777; }
+	.passed { color: #2e7d32; font-weight: bold; }
+	.failed { color: #c62828; font-weight: bold; }
+	svg text { font-size: 10px; fill: #555; }
+	@media print {
+		body { padding: 0; max-width: none; }
+		h2 { page-break-after: avoid; }
+		section { page-break-inside: avoid; }
+	}
+</style>
+</head>
+<body>
+<header>
+	<h1>TLS security report</h1>
+	<div><strong>{{.Domain.Url}}</strong>{{if .Domain.Title}} - {{.Domain.Title}}{{end}}</div>
+	<div class="muted">Last scan: {{date .Domain.UpdatedAt}} &middot; Generated: {{date .GeneratedAt}}</div>
+</header>
+
+<div class="summary">
+	<div class="card">
+		<div class="muted">Current grade</div>
+		<div class="value {{gradeClass .Domain.SslGrade}}">{{grade .Domain.SslGrade}}</div>
+		<div class="muted">Previous: {{grade .Domain.PreviousSslGrade}}</div>
+	</div>
+	<div class="card">
+		<div class="muted">Status</div>
+		<div class="value {{if .Domain.IsDown}}grade-bad{{else}}grade-good{{end}}">{{if .Domain.IsDown}}Down{{else}}Up{{end}}</div>
+		<div class="muted">{{len .Domain.Servers}} server(s)</div>
+	</div>
+	{{if .Domain.Headers}}
+	<div class="card">
+		<div class="muted">Security headers</div>
+		<div class="value {{gradeClass .Domain.Headers.Grade}}">{{grade .Domain.Headers.Grade}}</div>
+		<div class="muted">Score {{.Domain.Headers.Score}}/100</div>
+	</div>
+	{{end}}
+	{{if .Domain.Compliance}}
+	<div class="card">
+		<div class="muted">Policies</div>
+		<div class="value {{if .Domain.Compliance.Compliant}}grade-good{{else}}grade-bad{{end}}">{{if .Domain.Compliance.Compliant}}Compliant{{else}}Violating{{end}}</div>
+		<div class="muted">{{len .Domain.Compliance.Results}} rule(s)</div>
+	</div>
+	{{end}}
+</div>
+
+<section>
+	<h2>Servers</h2>
+	{{if .Domain.Servers}}
+	<table>
+		<tr><th>Address</th><th>Grade</th><th>Country</th><th>Owner</th><th>Certificate expires</th></tr>
+		{{range .Domain.Servers}}
+		<tr>
+			<td>{{.Address}}</td>
+			<td class="{{gradeClass .SslGrade}}">{{grade .SslGrade}}</td>
+			<td>{{.Country}}</td>
+			<td>{{.Owner}}</td>
+			<td>{{if .Certificate}}{{date .Certificate.NotAfter}}{{else}}-{{end}}</td>
+		</tr>
+		{{end}}
+	</table>
+	{{else}}
+	<p class="muted">No servers were found in the last scan.</p>
+	{{end}}
+</section>
+
+<section>
+	<h2>Grade history</h2>
+	{{if .Chart.Points}}
+	<svg width="{{.Chart.Width}}" height="{{.Chart.Height}}" viewBox="0 0 {{.Chart.Width}} {{.Chart.Height}}" role="img" aria-label="Grade history">
+		{{range .Chart.Levels}}
+		<line x1="{{$.Chart.Left}}" y1="{{.Y}}" x2="{{$.Chart.Right}}" y2="{{.Y}}" stroke="#eee"/>
+		<text x="4" y="{{.Y}}" dominant-baseline="middle">{{.Label}}</text>
+		{{end}}
+		<polyline points="{{.Chart.Points}}" fill="none" stroke="#1565c0" stroke-width="2"/>
+		{{range .Chart.Dots}}
+		<circle cx="{{.X}}" cy="{{.Y}}" r="3" fill="{{if .IsDown}}#c62828{{else}}#1565c0{{end}}"><title>{{.Label}}</title></circle>
+		{{end}}
+		<text x="{{.Chart.Left}}" y="{{.Chart.Height}}">{{date .Chart.From}}</text>
+		<text x="{{.Chart.Right}}" y="{{.Chart.Height}}" text-anchor="end">{{date .Chart.To}}</text>
+	</svg>
+	{{else}}
+	<p class="muted">There is no grade history for the last {{.HistoryDays}} days.</p>
+	{{end}}
+	<script type="application/json" id="grade-history">{{.History}}</script>
+</section>
+
+<section>
+	<h2>Detected changes</h2>
+	{{if .Changes}}
+	<ul>{{range .Changes}}<li>{{.}}</li>{{end}}</ul>
+	{{else}}
+	<p class="muted">No changes were detected in the last scan.</p>
+	{{end}}
+</section>
+
+<section>
+	<h2>Policy results</h2>
+	{{if and .Domain.Compliance .Domain.Compliance.Results}}
+	<table>
+		<tr><th>Policy</th><th>Type</th><th>Result</th><th>Reasons</th></tr>
+		{{range .Domain.Compliance.Results}}
+		<tr>
+			<td>{{.Policy}}</td>
+			<td>{{.Type}}</td>
+			<td>{{if .Passed}}<span class="passed">Passed</span>{{else}}<span class="failed">Failed</span>{{end}}</td>
+			<td>{{range .Reasons}}<div>{{.}}</div>{{end}}</td>
+		</tr>
+		{{end}}
+	</table>
+	{{else}}
+	<p class="muted">No policies are configured.</p>
+	{{end}}
+</section>
+</body>
+</html>
+`))
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// ScanThrottle: Structure used to keep a minimum time between the background scans, shared by every job
+// that calls SSL Labs so that together they respect its limits
+type ScanThrottle struct {
+	spacing time.Duration
+	mutex   sync.Mutex
+	last    time.Time
+}
+
+// NewScanThrottle: Receives the minimum time between two scans and stores it in the ScanThrottle structure
+// Params:
+// (spacing): Minimum time between two consecutive scans
+// Return:
+// (*ScanThrottle): Reference to the ScanThrottle object
+func NewScanThrottle(spacing time.Duration) *ScanThrottle {
+	return &ScanThrottle{spacing: spacing}
+}
+
+// Wait: Blocks until the spacing since the previous scan has passed, and registers the new scan
+func (t *ScanThrottle) Wait() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if wait := t.spacing - time.Since(t.last); wait > 0 {
+		time.Sleep(wait)
+	}
+	t.last = time.Now()
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// MinScanInterval: Shortest time allowed between two rescans of a domain
+const MinScanInterval = time.Hour
+
+// SchedulerService: Structure used to store the schedulerService functions
+type SchedulerService struct {
+	domainRepo      interfaces.IDomainRepository
+	scheduleRepo    interfaces.IScheduleRepository
+	domainService   interfaces.IDomainService
+	throttle        interfaces.IScanThrottle
+	defaultInterval time.Duration
+	spacing         time.Duration
+	tick            time.Duration
+	orgID           int64
+	logger          interfaces.ILogger
+}
+
+// NewSchedulerService: Receives the repositories and the domainService interface and stores them in the SchedulerService structure
+// Params:
+// (domainRepo): Reference to a domainRepo interface
+// (scheduleRepo): Reference to a scheduleRepo interface
+// (domainService): Reference to a domainService interface, used to rescan the domains
+// (throttle): Reference to the throttle shared by the background scans
+// (defaultInterval): Time between two rescans of a domain without a custom interval
+// (spacing): Minimum time between two consecutive rescans, used to size each round
+// (logger): Reference to the logger of the failed rounds and rescans
+// Return:
+// (*SchedulerService): Reference to the SchedulerService object
+func NewSchedulerService(domainRepo interfaces.IDomainRepository, scheduleRepo interfaces.IScheduleRepository, domainService interfaces.IDomainService, throttle interfaces.IScanThrottle, defaultInterval time.Duration, spacing time.Duration, logger interfaces.ILogger) *SchedulerService {
+	return &SchedulerService{domainRepo: domainRepo, scheduleRepo: scheduleRepo, domainService: domainService, throttle: throttle, defaultInterval: defaultInterval, spacing: spacing, tick: time.Minute, logger: logger}
+}
+
+// WithOrg: Returns a copy of the service that only sees, and only schedules, the domains of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.ISchedulerService): Scoped service
+func (s *SchedulerService) WithOrg(orgID int64) interfaces.ISchedulerService {
+	scoped := *s
+	scoped.domainRepo = s.domainRepo.WithOrg(orgID)
+	scoped.domainService = s.domainService.WithOrg(orgID)
+	scoped.orgID = orgID
+	return &scoped
+}
+
+// Start: Launches the background scheduler that rescans the due domains every tick
+func (s *SchedulerService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.tick)
+		defer ticker.Stop()
+		for {
+			if err := s.ScheduleDomains(); err != nil {
+				s.logger.Error(context.Background(), "Domains cannot be scheduled", "error", err)
+			}
+			if err := s.RunDueScans(); err != nil {
+				s.logger.Error(context.Background(), "Due scans failed", "error", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// ScheduleDomains: Creates a schedule for every domain that does not have one, read in a single query.
+// A first run that has already passed is placed at a random moment inside the interval so the rescans are spread
+// Return:
+// (error): Error if the process fails
+func (s *SchedulerService) ScheduleDomains() error {
+	schedules, err := s.scheduleRepo.FindUnscheduled(int64(s.defaultInterval.Seconds()))
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		if schedule.NextRunAt < time.Now().Unix() {
+			schedule.NextRunAt = time.Now().Unix()
+			if schedule.Interval > 0 {
+				schedule.NextRunAt += rand.Int63n(schedule.Interval)
+			}
+		}
+		if saveErr := s.scheduleRepo.Save(schedule); saveErr != nil {
+			return saveErr
+		}
+	}
+	return nil
+}
+
+// RunDueScans: Rescans, one by one and separated by the throttle, the domains whose next run has passed.
+// The next run is persisted before the scan so a restart does not repeat it
+// Return:
+// (error): Error if the process fails
+func (s *SchedulerService) RunDueScans() error {
+	limit := int(s.tick / s.spacing)
+	if limit < 1 {
+		limit = 1
+	}
+	schedules, err := s.scheduleRepo.FindDue(time.Now().Unix(), limit)
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		domain, domainErr := s.domainRepo.FindByID(schedule.DomainId)
+		if domainErr != nil {
+			s.logger.Warn(context.Background(), "Scheduled domain not found, the rescan is skipped", "domainId", schedule.DomainId, "error", domainErr)
+			continue
+		}
+		schedule.NextRunAt = time.Now().Unix() + schedule.Interval
+		if saveErr := s.scheduleRepo.Save(schedule); saveErr != nil {
+			return saveErr
+		}
+		s.throttle.Wait()
+		if _, checkErr := s.domainService.WithOrg(domain.OrgId).CheckDomain(domain.Url); checkErr != nil {
+			s.logger.Warn(context.Background(), "Scheduled rescan failed", "host", domain.Url, "error", checkErr)
+		}
+	}
+	return nil
+}
+
+// GetSchedule: Returns a JSON object with the rescan schedule of a domain
+// Params:
+// (hostPath): Host value of the path param
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *SchedulerService) GetSchedule(hostPath string) ([]byte, error) {
+	domain, domainErr := s.domainRepo.FindByUrl(hostPath)
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	schedule, scheduleErr := s.scheduleRepo.FindByDomain(domain.Id)
+	if scheduleErr != nil {
+		return nil, errors.New("Domain is not scheduled yet")
+	}
+	jsonBody, jsonError := json.Marshal(schedule)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// SetTagInterval: Stores the rescan interval of a tag and applies it to every domain that has the tag.
+// The domains tagged later, and the domains scheduled later, get it too. A domain with several such tags
+// is rescanned with the shortest interval
+// Params:
+// (tag): Tag of the domains
+// (interval): New interval, for example 24h or 7d
+// Return:
+// ([]byte): JSON object with the schedules of the domains that have the tag
+// (error): Error if the process fails
+func (s *SchedulerService) SetTagInterval(tag string, interval string) ([]byte, error) {
+	tags, err := normalizeTags([]string{tag})
+	if err != nil {
+		return nil, err
+	}
+	seconds, err := parseScanInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.scheduleRepo.SaveTagInterval(s.orgID, tags[0], seconds); err != nil {
+		return nil, err
+	}
+	domains, err := s.domainRepo.FindByTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]*models.Schedule, 0, len(domains))
+	for _, domain := range domains {
+		if err = s.ApplyTagInterval(domain); err != nil {
+			return nil, err
+		}
+		schedule, err := s.scheduleRepo.FindByDomain(domain.Id)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": schedules})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// SetInterval: Changes the rescan interval of a domain and moves its next run accordingly
+// Params:
+// (hostPath): Host value of the path param
+// (interval): New interval, for example 24h or 7d
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *SchedulerService) SetInterval(hostPath string, interval string) ([]byte, error) {
+	domain, domainErr := s.domainRepo.FindByUrl(hostPath)
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	schedule, err := s.setDomainInterval(domain, interval)
+	if err != nil {
+		return nil, err
+	}
+	jsonBody, jsonError := json.Marshal(schedule)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// ApplyTagInterval: Stores in the schedule of a domain the shortest interval of its tags. A domain whose tags have no interval keeps its schedule
+// Params:
+// (domain): Reference to the domain
+// Return:
+// (error): Error if the process fails
+func (s *SchedulerService) ApplyTagInterval(domain *models.Domain) error {
+	seconds, err := s.scheduleRepo.FindTagInterval(domain.Id)
+	if err != nil || seconds == 0 {
+		return err
+	}
+	_, err = s.saveDomainInterval(domain, seconds)
+	return err
+}
+
+// setDomainInterval: Auxiliary function that validates an interval and stores it in the schedule of a domain
+// Params:
+// (domain): Reference to the domain
+// (interval): New interval, for example 24h or 7d
+// Return:
+// (*models.Schedule): Reference to the stored schedule
+// (error): Error if the interval is invalid or the process fails
+func (s *SchedulerService) setDomainInterval(domain *models.Domain, interval string) (*models.Schedule, error) {
+	seconds, err := parseScanInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveDomainInterval(domain, seconds)
+}
+
+// saveDomainInterval: Auxiliary function that stores an interval in the schedule of a domain and moves its next run accordingly
+// Params:
+// (domain): Reference to the domain
+// (seconds): Interval in seconds
+// Return:
+// (*models.Schedule): Reference to the stored schedule
+// (error): Error if the process fails
+func (s *SchedulerService) saveDomainInterval(domain *models.Domain, seconds int64) (*models.Schedule, error) {
+	schedule := &models.Schedule{DomainId: domain.Id, Interval: seconds, NextRunAt: domain.UpdatedAt + seconds}
+	if schedule.NextRunAt < time.Now().Unix() {
+		schedule.NextRunAt = time.Now().Unix()
+	}
+	if saveErr := s.scheduleRepo.Save(schedule); saveErr != nil {
+		return nil, saveErr
+	}
+	return schedule, nil
+}
+
+// parseScanInterval: Auxiliary function that validates an interval such as 24h or 7d
+// Params:
+// (interval): Interval as a string
+// Return:
+// (int64): Interval in seconds
+// (error): Error if the interval is invalid or shorter than MinScanInterval
+func parseScanInterval(interval string) (int64, error) {
+	duration, parseErr := parseWindow(interval)
+	if parseErr != nil {
+		return 0, errors.New("Invalid interval")
+	}
+	if duration < MinScanInterval {
+		return 0, errors.New("Interval must be at least 1h")
+	}
+	return int64(duration.Seconds()), nil
+}
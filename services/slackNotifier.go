@@ -0,0 +1,38 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// SlackNotifier: Structure used to store the client used to call the Slack incoming webhooks
+type SlackNotifier struct {
+	client *http.Client
+}
+
+// NewSlackNotifier: Receives an http client and stores it in the SlackNotifier structure
+// Params:
+// (client): Reference to the http client
+// Return:
+// (*SlackNotifier): Reference to the SlackNotifier object
+func NewSlackNotifier(client *http.Client) *SlackNotifier {
+	return &SlackNotifier{client: client}
+}
+
+// Notify: Posts the event to a Slack compatible incoming webhook
+// Params:
+// (target): Incoming webhook url
+// (event): Reference to the event
+// Return:
+// (error): Error if the process fails
+func (n *SlackNotifier) Notify(target string, event *models.Event) error {
+	title, message := describeEvent(event)
+	payload := map[string]interface{}{
+		"text": title,
+		"attachments": []map[string]interface{}{
+			{"color": "#" + eventColor(event), "title": title, "text": message, "ts": event.OccurredAt},
+		},
+	}
+	return postJSON(n.client, target, payload)
+}
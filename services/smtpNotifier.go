@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// SmtpNotifier: Structure used to store the SMTP server configuration
+type SmtpNotifier struct {
+	addr     string
+	host     string
+	from     string
+	username string
+	password string
+}
+
+// NewSmtpNotifier: Receives the SMTP server configuration and stores it in the SmtpNotifier structure
+// Params:
+// (host): Host of the SMTP server
+// (port): Port of the SMTP server
+// (from): Sender address of the emails
+// (username): User for the PLAIN authentication, empty to send without authentication
+// (password): Password for the PLAIN authentication
+// Return:
+// (*SmtpNotifier): Reference to the SmtpNotifier object
+func NewSmtpNotifier(host string, port string, from string, username string, password string) *SmtpNotifier {
+	return &SmtpNotifier{addr: fmt.Sprintf("%s:%s", host, port), host: host, from: from, username: username, password: password}
+}
+
+// Notify: Sends the event by email
+// Params:
+// (target): Comma separated list of recipients
+// (event): Reference to the event
+// Return:
+// (error): Error if the process fails
+func (n *SmtpNotifier) Notify(target string, event *models.Event) error {
+	recipients := make([]string, 0)
+	for _, recipient := range strings.Split(target, ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("Channel has no recipients")
+	}
+	title, message := describeEvent(event)
+	body := strings.Join([]string{
+		fmt.Sprintf("From: %s", n.from),
+		fmt.Sprintf("To: %s", strings.Join(recipients, ", ")),
+		fmt.Sprintf("Subject: %s", title),
+		fmt.Sprintf("Date: %s", time.Unix(event.OccurredAt, 0).UTC().Format(time.RFC1123Z)),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		message,
+		"",
+	}, "\r\n")
+	var auth smtp.Auth
+	if n.username != "" {
+		auth = smtp.PlainAuth("", n.username, n.password, n.host)
+	}
+	return smtp.SendMail(n.addr, auth, n.from, recipients, []byte(body))
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// fakeSmtpMessage: Envelope and data received by the fake SMTP server
+type fakeSmtpMessage struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// startFakeSmtpServer: Starts an SMTP server on a loopback port that accepts one message, without extensions
+// so the client does not try STARTTLS or authentication
+func startFakeSmtpServer(t *testing.T) (string, string, <-chan fakeSmtpMessage) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	messages := make(chan fakeSmtpMessage, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		message := fakeSmtpMessage{}
+		reply("220 localhost fake SMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				message.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				message.recipients = append(message.recipients, strings.Trim(line[len("RCPT TO:"):], "<> "))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				message.data = data.String()
+				reply("250 OK")
+				messages <- message
+			case command == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, port, messages
+}
+
+func TestSmtpNotifierSendsEnvelopeAndBody(t *testing.T) {
+	host, port, messages := startFakeSmtpServer(t)
+	notifier := NewSmtpNotifier(host, port, "alerts@example.com", "", "")
+	event := &models.Event{
+		Type:       models.EventGradeDowngraded,
+		Url:        "example.com",
+		OccurredAt: 1600000000,
+		Domain:     &models.Domain{Url: "example.com", PreviousSslGrade: models.GradeA, SslGrade: models.GradeC},
+	}
+	if err := notifier.Notify(" ops@example.com, ,sec@example.com ", event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	var message fakeSmtpMessage
+	select {
+	case message = <-messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fake SMTP server received no message")
+	}
+	if message.from != "alerts@example.com" {
+		t.Errorf("MAIL FROM = %q, want alerts@example.com", message.from)
+	}
+	if strings.Join(message.recipients, ",") != "ops@example.com,sec@example.com" {
+		t.Errorf("RCPT TO = %v, want ops@example.com and sec@example.com", message.recipients)
+	}
+	for _, want := range []string{
+		"From: alerts@example.com\r\n",
+		"To: ops@example.com, sec@example.com\r\n",
+		"Subject: example.com grade downgraded\r\n",
+		"Date: " + time.Unix(1600000000, 0).UTC().Format(time.RFC1123Z) + "\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"\r\nThe SSL grade of example.com dropped from A to C.\r\n",
+	} {
+		if strings.Contains(message.data, want) == false {
+			t.Errorf("data misses %q:\n%s", want, message.data)
+		}
+	}
+}
+
+func TestSmtpNotifierRequiresRecipients(t *testing.T) {
+	notifier := NewSmtpNotifier("127.0.0.1", "1", "alerts@example.com", "", "")
+	if err := notifier.Notify(" , ", &models.Event{Type: models.EventDomainDown, Url: "example.com"}); err == nil {
+		t.Fatal("Notify accepted a channel without recipients")
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+)
+
+// statsTop: Number of owners and countries returned in the stats
+const statsTop = 10
+
+// StatsService: Structure used to store the statsService functions
+type StatsService struct {
+	statsRepo interfaces.IStatsRepository
+}
+
+// NewStatsService: Receives a reference to the statsRepo interface and stores it in the StatsService structure
+// Params:
+// (statsRepo): Reference to a statsRepo interface
+// Return:
+// (*StatsService): Reference to the StatsService object
+func NewStatsService(statsRepo interfaces.IStatsRepository) *StatsService {
+	return &StatsService{statsRepo: statsRepo}
+}
+
+// WithOrg: Returns a copy of the service that only aggregates the domains of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IStatsService): Scoped service
+func (s *StatsService) WithOrg(orgID int64) interfaces.IStatsService {
+	return &StatsService{statsRepo: s.statsRepo.WithOrg(orgID)}
+}
+
+// GetStats: Returns a JSON object with the aggregate view of the tracked domains
+// Params:
+// (days): Window, in days, of the domains counted in servers_changed
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *StatsService) GetStats(days int) ([]byte, error) {
+	if days <= 0 {
+		return nil, errors.New("Invalid days")
+	}
+	now := time.Now()
+	stats, err := s.statsRepo.GetStats(now.Unix(), now.Add(-time.Duration(days)*24*time.Hour).Unix(), statsTop)
+	if err != nil {
+		return nil, err
+	}
+	stats.ServersChangedDays = days
+	stats.GeneratedAt = now.Unix()
+	jsonBody, jsonError := json.Marshal(stats)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
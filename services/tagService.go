@@ -0,0 +1,183 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// tagRegex: Regex used to validate the tags, such as prod, customer:acme or team:payments
+var tagRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:/-]{0,63}$`)
+
+// TagService: Structure used to store the tagService functions
+type TagService struct {
+	domainRepo interfaces.IDomainRepository
+	tagRepo    interfaces.ITagRepository
+	scheduler  interfaces.ISchedulerService
+}
+
+// NewTagService: Receives a reference to the domainRepo and tagRepo interfaces and the scheduler and stores them in the TagService structure
+// Params:
+// (domainRepo): Reference to a domainRepo interface
+// (tagRepo): Reference to a tagRepo interface
+// (scheduler): Reference to the scheduler, which applies the interval of the tags added to a domain
+// Return:
+// (*TagService): Reference to the TagService object
+func NewTagService(domainRepo interfaces.IDomainRepository, tagRepo interfaces.ITagRepository, scheduler interfaces.ISchedulerService) *TagService {
+	return &TagService{domainRepo: domainRepo, tagRepo: tagRepo, scheduler: scheduler}
+}
+
+// WithOrg: Returns a copy of the service that only sees the domains and tags of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.ITagService): Scoped service
+func (s *TagService) WithOrg(orgID int64) interfaces.ITagService {
+	return &TagService{domainRepo: s.domainRepo.WithOrg(orgID), tagRepo: s.tagRepo.WithOrg(orgID), scheduler: s.scheduler.WithOrg(orgID)}
+}
+
+// GetTags: Returns a JSON object with the tags in use and the number of domains of each one
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *TagService) GetTags() ([]byte, error) {
+	tags, err := s.tagRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": tags})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// GetDomainTags: Returns a JSON object with the tags of a domain
+// Params:
+// (hostPath): Host of the domain
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *TagService) GetDomainTags(hostPath string) ([]byte, error) {
+	domain, err := s.domainRepo.FindByUrl(hostPath)
+	if err != nil {
+		return nil, errors.New("Domain not found")
+	}
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": domain.Tags})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// AddDomainTags: Attaches tags to a domain and applies to its schedule the interval of its tags, if they have one
+// Params:
+// (hostPath): Host of the domain
+// (body): JSON body such as {"tags": ["prod", "customer:acme"]}
+// Return:
+// ([]byte): JSON object with every tag of the domain
+// (error): Error if the process fails
+func (s *TagService) AddDomainTags(hostPath string, body []byte) ([]byte, error) {
+	var request struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil || len(request.Tags) == 0 {
+		return nil, errors.New("Invalid body")
+	}
+	tags, err := normalizeTags(request.Tags)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := s.domainRepo.FindByUrl(hostPath)
+	if err != nil {
+		return nil, errors.New("Domain not found")
+	}
+	for _, tag := range tags {
+		if err := s.tagRepo.Add(domain.Id, tag); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.scheduler.ApplyTagInterval(domain); err != nil {
+		return nil, err
+	}
+	return s.GetDomainTags(hostPath)
+}
+
+// RemoveDomainTag: Detaches a tag from a domain
+// Params:
+// (hostPath): Host of the domain
+// (tag): Tag to be detached
+// Return:
+// (error): Error if the process fails
+func (s *TagService) RemoveDomainTag(hostPath string, tag string) error {
+	domain, err := s.domainRepo.FindByUrl(hostPath)
+	if err != nil {
+		return errors.New("Domain not found")
+	}
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if hasTag(domain.Tags, []string{tag}) == false {
+		return errors.New("Tag not found")
+	}
+	return s.tagRepo.Remove(domain.Id, tag)
+}
+
+// normalizeTags: Auxiliary function that lowercases, validates and deduplicates a tag list
+// Params:
+// (tags): Tags as they were received
+// Return:
+// ([]string): Normalized tags, never nil
+// (error): Error if a tag is invalid
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tagRegex.MatchString(tag) == false {
+			return nil, fmt.Errorf("Invalid tag %s", tag)
+		}
+		if seen[tag] == false {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// hasTag: Auxiliary function that checks if a tag list contains one of the wanted tags.
+// An empty wanted list matches every tag list, so it is used to scope policies and channels
+// Params:
+// (tags): Tags of a domain
+// (wanted): Tags of the scope
+// Return:
+// (bool): True if the scope matches. False if not
+func hasTag(tags []string, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		for _, wantedTag := range wanted {
+			if tag == wantedTag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// domainTags: Auxiliary function that returns the tags of a domain, nil safe
+// Params:
+// (domain): Reference to the domain
+// Return:
+// ([]string): Tags of the domain
+func domainTags(domain *models.Domain) []string {
+	if domain == nil {
+		return []string{}
+	}
+	return domain.Tags
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// TeamsNotifier: Structure used to store the client used to call the Microsoft Teams connectors
+type TeamsNotifier struct {
+	client *http.Client
+}
+
+// NewTeamsNotifier: Receives an http client and stores it in the TeamsNotifier structure
+// Params:
+// (client): Reference to the http client
+// Return:
+// (*TeamsNotifier): Reference to the TeamsNotifier object
+func NewTeamsNotifier(client *http.Client) *TeamsNotifier {
+	return &TeamsNotifier{client: client}
+}
+
+// Notify: Posts the event as a message card to a Microsoft Teams connector
+// Params:
+// (target): Connector url
+// (event): Reference to the event
+// Return:
+// (error): Error if the process fails
+func (n *TeamsNotifier) Notify(target string, event *models.Event) error {
+	title, message := describeEvent(event)
+	facts := []map[string]string{{"name": "Domain", "value": event.Url}, {"name": "Event", "value": event.Type}}
+	if event.Domain != nil {
+		facts = append(facts, map[string]string{"name": "SSL grade", "value": string(event.Domain.SslGrade)})
+	}
+	payload := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    title,
+		"themeColor": eventColor(event),
+		"title":      title,
+		"text":       message,
+		"sections":   []map[string]interface{}{{"facts": facts}},
+	}
+	return postJSON(n.client, target, payload)
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// tokenLeeway: Clock skew tolerated when checking the expiry and not-before claims
+const tokenLeeway = time.Minute
+
+// DefaultRoleScopes: Scopes granted to each role when no mapping is configured
+var DefaultRoleScopes = map[string][]string{
+	"viewer": {models.ScopeDomainsRead},
+	"editor": {models.ScopeDomainsRead, models.ScopeDomainsWrite, models.ScopeScansTrigger},
+	"admin":  {models.ScopeAdmin},
+}
+
+// tokenAlgorithms: Hash of each supported signature algorithm. HMAC and none are not supported,
+// the backend only holds the public keys of the identity provider
+var tokenAlgorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// TokenVerifier: Structure used to store the keys and the expected claims of the bearer tokens
+type TokenVerifier struct {
+	keys       interfaces.IJwksSource
+	issuer     string
+	audience   string
+	rolesClaim string
+	orgClaim   string
+	roleScopes map[string][]string
+}
+
+// NewTokenVerifier: Receives the keys and the expected claims and stores them in the TokenVerifier structure
+// Params:
+// (keys): Reference to the source of the public keys of the identity provider
+// (issuer): Expected iss claim
+// (audience): Expected aud claim
+// (rolesClaim): Claim with the roles of the user, a dotted path such as realm_access.roles is followed
+// (orgClaim): Claim with the id of the organization of the user, the default organization if the token has none
+// (roleScopes): Scopes granted to each role, DefaultRoleScopes if empty
+// Return:
+// (*TokenVerifier): Reference to the TokenVerifier object
+func NewTokenVerifier(keys interfaces.IJwksSource, issuer string, audience string, rolesClaim string, orgClaim string, roleScopes map[string][]string) *TokenVerifier {
+	if len(roleScopes) == 0 {
+		roleScopes = DefaultRoleScopes
+	}
+	return &TokenVerifier{keys: keys, issuer: issuer, audience: audience, rolesClaim: rolesClaim, orgClaim: orgClaim, roleScopes: roleScopes}
+}
+
+// Verify: Checks the signature, issuer, audience and validity period of a JWT and maps its claims to a principal
+// Params:
+// (token): Compact serialized JWT
+// Return:
+// (*models.Principal): Reference to the principal of the user, with the scopes of its roles
+// (error): Error if the token is invalid
+func (v *TokenVerifier) Verify(token string) (*models.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("Malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeTokenPart(parts[0], &header); err != nil {
+		return nil, errors.New("Malformed token")
+	}
+	hash, ok := tokenAlgorithms[header.Alg]
+	if ok == false {
+		return nil, fmt.Errorf("Unsupported algorithm %s", header.Alg)
+	}
+	key, err := v.keys.Key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("Malformed token")
+	}
+	hasher := hash.New()
+	hasher.Write([]byte(parts[0] + "." + parts[1]))
+	if err = verifySignature(header.Alg, key, hash, hasher.Sum(nil), signature); err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	if err = decodeTokenPart(parts[1], &claims); err != nil {
+		return nil, errors.New("Malformed token")
+	}
+	if err = v.checkClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+	return v.principal(claims)
+}
+
+// checkClaims: Checks the issuer, audience, expiry and not-before claims
+// Params:
+// (claims): Claims of the token
+// (now): Moment of the check
+// Return:
+// (error): Error if a claim is invalid
+func (v *TokenVerifier) checkClaims(claims map[string]interface{}, now time.Time) error {
+	if issuer, _ := claims["iss"].(string); issuer != v.issuer {
+		return errors.New("Invalid token issuer")
+	}
+	if hasAudience(claims["aud"], v.audience) == false {
+		return errors.New("Invalid token audience")
+	}
+	expiresAt, ok := claims["exp"].(float64)
+	if ok == false {
+		return errors.New("Token has no expiry")
+	}
+	if now.Add(-tokenLeeway).After(time.Unix(int64(expiresAt), 0)) {
+		return errors.New("Token is expired")
+	}
+	if notBefore, ok := claims["nbf"].(float64); ok && now.Add(tokenLeeway).Before(time.Unix(int64(notBefore), 0)) {
+		return errors.New("Token is not valid yet")
+	}
+	return nil
+}
+
+// maxSafeInteger: Largest integer a JSON number decoded as float64 holds exactly
+const maxSafeInteger = 1<<53 - 1
+
+// principal: Maps the claims of a verified token to a principal. The organization claim must be a positive integer,
+// as a number or a string, so a token can never be scoped to the unscoped value of the repositories
+// Params:
+// (claims): Claims of the token
+// Return:
+// (*models.Principal): Reference to the principal
+// (error): Error if the organization claim is not a positive integer
+func (v *TokenVerifier) principal(claims map[string]interface{}) (*models.Principal, error) {
+	subject, _ := claims["sub"].(string)
+	principal := &models.Principal{Subject: "user:" + subject, OrgId: models.DefaultOrgId, Scopes: []string{}}
+	switch org := claimPath(claims, v.orgClaim).(type) {
+	case nil:
+	case float64:
+		if org != math.Trunc(org) || org < 1 || org > maxSafeInteger {
+			return nil, errors.New("Invalid organization claim")
+		}
+		principal.OrgId = int64(org)
+	case string:
+		orgID, err := strconv.ParseInt(org, 10, 64)
+		if err != nil || orgID <= 0 {
+			return nil, errors.New("Invalid organization claim")
+		}
+		principal.OrgId = orgID
+	default:
+		return nil, errors.New("Invalid organization claim")
+	}
+	granted := make(map[string]bool)
+	for role := range stringClaims(claimPath(claims, v.rolesClaim)) {
+		for _, scope := range v.roleScopes[role] {
+			if granted[scope] == false {
+				granted[scope] = true
+				principal.Scopes = append(principal.Scopes, scope)
+			}
+		}
+	}
+	return principal, nil
+}
+
+// verifySignature: Auxiliary function that checks the signature of a token with the key of its algorithm family
+// Params:
+// (alg): Algorithm of the token
+// (key): Public key
+// (hash): Hash function of the algorithm
+// (digest): Hash of the signed part of the token
+// (signature): Signature of the token
+// Return:
+// (error): Error if the signature is invalid or the key does not match the algorithm
+func verifySignature(alg string, key crypto.PublicKey, hash crypto.Hash, digest []byte, signature []byte) error {
+	invalid := errors.New("Invalid token signature")
+	switch alg[:2] {
+	case "RS", "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if ok == false {
+			return invalid
+		}
+		if alg[:2] == "RS" {
+			if rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature) != nil {
+				return invalid
+			}
+			return nil
+		}
+		if rsa.VerifyPSS(rsaKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) != nil {
+			return invalid
+		}
+		return nil
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if ok == false {
+			return invalid
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return invalid
+		}
+		r, s := new(big.Int).SetBytes(signature[:size]), new(big.Int).SetBytes(signature[size:])
+		if ecdsa.Verify(ecKey, digest, r, s) == false {
+			return invalid
+		}
+		return nil
+	}
+	return invalid
+}
+
+// hasAudience: Auxiliary function that checks the aud claim, which can be a string or an array of strings
+// Params:
+// (claim): Value of the aud claim
+// (audience): Expected audience
+// Return:
+// (bool): True if the token is meant for the audience. False if not
+func hasAudience(claim interface{}, audience string) bool {
+	switch typed := claim.(type) {
+	case string:
+		return typed == audience
+	case []interface{}:
+		for _, item := range typed {
+			if value, ok := item.(string); ok && value == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeTokenPart: Auxiliary function that decodes a base64url JSON part of a token
+// Params:
+// (part): Encoded part
+// (value): Reference to the decoded value
+// Return:
+// (error): Error if the part is malformed
+func decodeTokenPart(part string, value interface{}) error {
+	content, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, value)
+}
+
+// claimPath: Auxiliary function that follows a dotted path through nested claims
+// Params:
+// (claims): Claims of the token
+// (path): Dotted path, such as realm_access.roles
+// Return:
+// (interface{}): Value of the claim, nil if it does not exist
+func claimPath(claims map[string]interface{}, path string) interface{} {
+	var value interface{} = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if ok == false {
+			return nil
+		}
+		value = object[name]
+	}
+	return value
+}
+
+// stringClaims: Auxiliary function that reads a claim that can be a string, a space separated string or an array of strings
+// Params:
+// (claim): Value of the claim
+// Return:
+// (map[string]bool): Set of the values
+func stringClaims(claim interface{}) map[string]bool {
+	values := make(map[string]bool)
+	switch typed := claim.(type) {
+	case string:
+		for _, value := range strings.Fields(typed) {
+			values[value] = true
+		}
+	case []interface{}:
+		for _, item := range typed {
+			if value, ok := item.(string); ok {
+				values[value] = true
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+const (
+	testIssuer   = "https://id.example.com"
+	testAudience = "tr-backend"
+)
+
+type testSigner struct {
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey: %v", err)
+	}
+	return &testSigner{rsaKey: rsaKey, ecKey: ecKey}
+}
+
+// jwks: Returns the JWKS document with the public keys of the signer, with ids "rsa" and "ec"
+func (s *testSigner) jwks(t *testing.T) []byte {
+	encode := base64.RawURLEncoding.EncodeToString
+	size := (s.ecKey.Curve.Params().BitSize + 7) / 8
+	document := map[string]interface{}{"keys": []map[string]string{
+		{"kid": "rsa", "kty": "RSA", "n": encode(s.rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(s.rsaKey.E)).Bytes())},
+		{"kid": "ec", "kty": "EC", "crv": "P-256", "x": encode(padded(s.ecKey.X, size)), "y": encode(padded(s.ecKey.Y, size))},
+	}}
+	content, err := json.Marshal(document)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	return content
+}
+
+// sign: Returns a compact JWT with the given header algorithm and claims, signed with the key of the algorithm
+func (s *testSigner) sign(t *testing.T, alg string, kid string, claims map[string]interface{}) string {
+	encodeJson := func(value interface{}) string {
+		content, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(content)
+	}
+	signed := encodeJson(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encodeJson(claims)
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch alg {
+	case "RS256":
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, s.rsaKey, crypto.SHA256, digest[:]); err != nil {
+			t.Fatalf("rsa.SignPKCS1v15: %v", err)
+		}
+	case "ES256":
+		r, sig, err := ecdsa.Sign(rand.Reader, s.ecKey, digest[:])
+		if err != nil {
+			t.Fatalf("ecdsa.Sign: %v", err)
+		}
+		signature = append(padded(r, 32), padded(sig, 32)...)
+	default:
+		signature = []byte("signature")
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func padded(value *big.Int, size int) []byte {
+	content := value.Bytes()
+	return append(make([]byte, size-len(content)), content...)
+}
+
+func writeTestJwks(t *testing.T, content []byte) string {
+	file, err := ioutil.TempFile("", "jwks-*.json")
+	if err != nil {
+		t.Fatalf("ioutil.TempFile: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(file.Name()) })
+	if _, err = file.Write(content); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	file.Close()
+	return file.Name()
+}
+
+func newTestVerifier(t *testing.T, signer *testSigner) *TokenVerifier {
+	source, err := NewJwksSource(writeTestJwks(t, signer.jwks(t)))
+	if err != nil {
+		t.Fatalf("NewJwksSource: %v", err)
+	}
+	return NewTokenVerifier(source, testIssuer, testAudience, "realm_access.roles", "org_id", nil)
+}
+
+func validClaims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss": testIssuer,
+		"aud": []string{"other", testAudience},
+		"sub": "alice",
+		"exp": now.Add(time.Hour).Unix(),
+		"nbf": now.Add(-time.Minute).Unix(),
+	}
+}
+
+func TestTokenVerifierAcceptsSignedTokens(t *testing.T) {
+	signer := newTestSigner(t)
+	verifier := newTestVerifier(t, signer)
+	for _, test := range []struct{ alg, kid string }{{"RS256", "rsa"}, {"ES256", "ec"}} {
+		principal, err := verifier.Verify(signer.sign(t, test.alg, test.kid, validClaims()))
+		if err != nil {
+			t.Fatalf("%s: Verify: %v", test.alg, err)
+		}
+		if principal.Subject != "user:alice" || principal.OrgId != models.DefaultOrgId {
+			t.Errorf("%s: principal = %+v", test.alg, principal)
+		}
+	}
+}
+
+func TestTokenVerifierRejectsInvalidTokens(t *testing.T) {
+	signer := newTestSigner(t)
+	verifier := newTestVerifier(t, signer)
+	with := func(name string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+	now := time.Now()
+	cases := []struct {
+		name  string
+		token string
+	}{
+		{"wrong issuer", signer.sign(t, "RS256", "rsa", with("iss", "https://evil.example.com"))},
+		{"wrong audience", signer.sign(t, "ES256", "ec", with("aud", "other"))},
+		{"no expiry", signer.sign(t, "RS256", "rsa", with("exp", nil))},
+		{"expired", signer.sign(t, "ES256", "ec", with("exp", now.Add(-2*tokenLeeway).Unix()))},
+		{"not valid yet", signer.sign(t, "RS256", "rsa", with("nbf", now.Add(2*tokenLeeway).Unix()))},
+		{"none algorithm", signer.sign(t, "none", "rsa", validClaims())},
+		{"hmac algorithm", signer.sign(t, "HS256", "rsa", validClaims())},
+		{"unknown key", signer.sign(t, "RS256", "missing", validClaims())},
+		{"key of another family", signer.sign(t, "RS256", "ec", validClaims())},
+		{"zero organization", signer.sign(t, "RS256", "rsa", with("org_id", 0))},
+		{"negative organization", signer.sign(t, "RS256", "rsa", with("org_id", -3))},
+		{"fractional organization", signer.sign(t, "RS256", "rsa", with("org_id", 1.5))},
+		{"zero organization string", signer.sign(t, "RS256", "rsa", with("org_id", "0"))},
+		{"malformed", "not.a-token"},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			if principal, err := verifier.Verify(test.token); err == nil {
+				t.Errorf("Verify accepted the token: %+v", principal)
+			}
+		})
+	}
+
+	valid := signer.sign(t, "ES256", "ec", validClaims())
+	tampered := valid[:len(valid)-4] + "AAAA"
+	if _, err := verifier.Verify(tampered); err == nil {
+		t.Error("Verify accepted a tampered signature")
+	}
+}
+
+func TestTokenVerifierMapsRolesToScopes(t *testing.T) {
+	signer := newTestSigner(t)
+	verifier := newTestVerifier(t, signer)
+	cases := []struct {
+		name  string
+		roles interface{}
+		org   interface{}
+		want  []string
+		orgId int64
+	}{
+		{"no roles", nil, nil, []string{}, models.DefaultOrgId},
+		{"viewer", []string{"viewer"}, 7, []string{models.ScopeDomainsRead}, 7},
+		{"editor", []string{"editor", "unknown"}, "8", []string{models.ScopeDomainsRead, models.ScopeDomainsWrite, models.ScopeScansTrigger}, 8},
+		{"viewer and editor", []string{"viewer", "editor"}, nil, []string{models.ScopeDomainsRead, models.ScopeDomainsWrite, models.ScopeScansTrigger}, models.DefaultOrgId},
+		{"admin as space separated string", "admin other", nil, []string{models.ScopeAdmin}, models.DefaultOrgId},
+	}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			claims := validClaims()
+			if test.roles != nil {
+				claims["realm_access"] = map[string]interface{}{"roles": test.roles}
+			}
+			if test.org != nil {
+				claims["org_id"] = test.org
+			}
+			principal, err := verifier.Verify(signer.sign(t, "RS256", "rsa", claims))
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if principal.OrgId != test.orgId {
+				t.Errorf("OrgId = %d, want %d", principal.OrgId, test.orgId)
+			}
+			got := make(map[string]bool)
+			for _, scope := range principal.Scopes {
+				got[scope] = true
+			}
+			want := make(map[string]bool)
+			for _, scope := range test.want {
+				want[scope] = true
+			}
+			if len(principal.Scopes) != len(got) || reflect.DeepEqual(got, want) == false {
+				t.Errorf("Scopes = %v, want %v", principal.Scopes, test.want)
+			}
+		})
+	}
+}
+
+func TestJwksSourceRefreshesRemoteKeys(t *testing.T) {
+	signer := newTestSigner(t)
+	document := signer.jwks(t)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write(document)
+	}))
+	defer server.Close()
+	source, err := NewJwksSource(server.URL)
+	if err != nil {
+		t.Fatalf("NewJwksSource: %v", err)
+	}
+	if _, err = source.Key("rsa"); err != nil || atomic.LoadInt32(&requests) != 1 {
+		t.Fatalf("Key(rsa) = %v after %d requests, want the loaded key without a new request", err, requests)
+	}
+
+	if _, err = source.Key("missing"); err == nil || atomic.LoadInt32(&requests) != 1 {
+		t.Fatalf("Key(missing) = %v after %d requests, want an error without a new request within the miss interval", err, requests)
+	}
+
+	source.mutex.Lock()
+	source.fetchedAt = time.Now().Add(-jwksMissRefreshInterval)
+	source.mutex.Unlock()
+	if _, err = source.Key("missing"); err == nil || atomic.LoadInt32(&requests) != 2 {
+		t.Fatalf("Key(missing) = %v after %d requests, want an error after one new request", err, requests)
+	}
+	if _, err = source.Key("missing"); atomic.LoadInt32(&requests) != 2 {
+		t.Fatalf("Key(missing) fetched again right after a miss, %d requests", requests)
+	}
+
+	source.mutex.Lock()
+	source.fetchedAt = time.Now().Add(-jwksRefreshInterval)
+	source.mutex.Unlock()
+	if _, err = source.Key("ec"); err != nil {
+		t.Fatalf("Key(ec): %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&requests) != 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Fatalf("periodic refresh made %d requests, want 3", got)
+	}
+}
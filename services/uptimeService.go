@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// UptimeService: Structure used to store the uptimeService functions
+type UptimeService struct {
+	domainRepo interfaces.IDomainRepository
+	probeRepo  interfaces.IProbeRepository
+	publisher  interfaces.IEventPublisher
+	interval   time.Duration
+	client     *http.Client
+	logger     interfaces.ILogger
+}
+
+// NewUptimeService: Receives the domainRepo, probeRepo and publisher interfaces, the probe interval and the logger and stores them in the UptimeService structure
+// Params:
+// (domainRepo): Reference to a domainRepo interface
+// (probeRepo): Reference to a probeRepo interface
+// (publisher): Reference to the publisher that receives the down and up events
+// (interval): Time between two probes of the same domain
+// (logger): Reference to the logger of the probes that cannot be stored
+// Return:
+// (*UptimeService): Reference to the UptimeService object
+func NewUptimeService(domainRepo interfaces.IDomainRepository, probeRepo interfaces.IProbeRepository, publisher interfaces.IEventPublisher, interval time.Duration, logger interfaces.ILogger) *UptimeService {
+	return &UptimeService{domainRepo: domainRepo, probeRepo: probeRepo, publisher: publisher, interval: interval, client: &http.Client{Timeout: 10 * time.Second}, logger: logger}
+}
+
+// WithOrg: Returns a copy of the service that only sees the domains of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IUptimeService): Scoped service
+func (s *UptimeService) WithOrg(orgID int64) interfaces.IUptimeService {
+	scoped := *s
+	scoped.domainRepo = s.domainRepo.WithOrg(orgID)
+	return &scoped
+}
+
+// Start: Launches the background monitor that probes every tracked domain each interval
+func (s *UptimeService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.ProbeDomains()
+			<-ticker.C
+		}
+	}()
+}
+
+// ProbeDomains: Probes all the domains stored in the database
+func (s *UptimeService) ProbeDomains() {
+	domains, err := s.domainRepo.GetAll()
+	if err != nil {
+		s.logger.Error(context.Background(), "Domains cannot be read, the probes are skipped", "error", err)
+		return
+	}
+	for _, domain := range domains {
+		if _, probeErr := s.ProbeDomain(domain); probeErr != nil {
+			s.logger.Warn(context.Background(), "Probe cannot be stored", "host", domain.Url, "error", probeErr)
+		}
+	}
+}
+
+// ProbeDomain: Makes a request to the domain, stores the outcome and, if the IsDown flag of the domain changed, updates only that flag
+// and publishes the event. The event is skipped when the stored flag already had the new value
+// Params:
+// (domain): Reference to the domain to be probed
+// Return:
+// (*models.Probe): Reference to the stored probe
+// (error): Error if the process fails
+func (s *UptimeService) ProbeDomain(domain *models.Domain) (*models.Probe, error) {
+	url := domain.Url
+	if strings.HasPrefix(url, "ht") == false {
+		url = fmt.Sprintf("https://%s", url)
+	}
+	probe := &models.Probe{DomainId: domain.Id, CheckedAt: time.Now().Unix()}
+	start := time.Now()
+	resp, err := s.client.Get(url)
+	probe.ResponseTime = time.Since(start).Milliseconds()
+	if err != nil {
+		probe.Error = err.Error()
+	} else {
+		resp.Body.Close()
+		probe.StatusCode = resp.StatusCode
+		probe.IsUp = resp.StatusCode < 500
+	}
+	id, saveErr := s.probeRepo.Save(probe)
+	if saveErr != nil {
+		return nil, saveErr
+	}
+	probe.Id = id
+	if domain.IsDown == probe.IsUp {
+		changed, updatedErr := s.domainRepo.SetDown(domain.Id, !probe.IsUp)
+		if updatedErr != nil {
+			return nil, updatedErr
+		}
+		domain.IsDown = !probe.IsUp
+		if changed == false {
+			return probe, nil
+		}
+		eventType := models.EventDomainUp
+		if domain.IsDown {
+			eventType = models.EventDomainDown
+		}
+		s.publisher.Publish(&models.Event{Type: eventType, Url: domain.Url, OccurredAt: probe.CheckedAt, Domain: domain})
+	}
+	return probe, nil
+}
+
+// GetUptime: Returns a JSON object with the availability, incidents and mean response time of a domain
+// Params:
+// (hostPath): Host value of the path param
+// (window): Period to be summarized, for example 30d or 12h
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *UptimeService) GetUptime(hostPath string, window string) ([]byte, error) {
+	if window == "" {
+		window = "30d"
+	}
+	duration, parseErr := parseWindow(window)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	domain, domainErr := s.domainRepo.FindByUrl(hostPath)
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	since := time.Now().Add(-duration).Unix()
+	probes, probesErr := s.probeRepo.FindByDomainSince(domain.Id, since)
+	if probesErr != nil {
+		return nil, probesErr
+	}
+	uptime := summarizeProbes(probes)
+	uptime.Url = domain.Url
+	uptime.Window = window
+	jsonBody, jsonError := json.Marshal(uptime)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// summarizeProbes: Auxiliary function that takes a probe slice ordered by date and computes the uptime of the period
+// Params:
+// (probes): Probe slice
+// Return:
+// (*models.Uptime): Reference to the uptime object
+func summarizeProbes(probes []*models.Probe) *models.Uptime {
+	uptime := &models.Uptime{Probes: len(probes), Incidents: []models.Incident{}}
+	if len(probes) == 0 {
+		return uptime
+	}
+	upProbes := 0
+	responseTime := int64(0)
+	var incident *models.Incident
+	for _, probe := range probes {
+		if probe.IsUp {
+			upProbes++
+			responseTime += probe.ResponseTime
+			if incident != nil {
+				incident.DownTo = probe.CheckedAt
+				uptime.Incidents = append(uptime.Incidents, *incident)
+				incident = nil
+			}
+		} else if incident == nil {
+			incident = &models.Incident{DownFrom: probe.CheckedAt}
+		}
+	}
+	if incident != nil {
+		uptime.Incidents = append(uptime.Incidents, *incident)
+	}
+	uptime.Availability = float64(upProbes) * 100 / float64(len(probes))
+	if upProbes > 0 {
+		uptime.MeanResponseTime = float64(responseTime) / float64(upProbes)
+	}
+	return uptime
+}
+
+// parseWindow: Auxiliary function that converts a window such as 30d, 12h or 15m into a duration
+// Params:
+// (window): Window value of the query param
+// Return:
+// (time.Duration): Duration of the window
+// (error): Error if the window is invalid
+func parseWindow(window string) (time.Duration, error) {
+	if strings.HasSuffix(window, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(window, "d"))
+		if err != nil || days <= 0 {
+			return 0, errors.New("Invalid window")
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration <= 0 {
+		return 0, errors.New("Invalid window")
+	}
+	return duration, nil
+}
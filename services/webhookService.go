@@ -0,0 +1,259 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	interfaces "github.com/JonatanOrdonez/tr-backend/interfaces"
+
+	"github.com/JonatanOrdonez/tr-backend/models"
+)
+
+// WebhookService: Structure used to store the webhookService functions
+type WebhookService struct {
+	webhookRepo interfaces.IWebhookRepository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewWebhookService: Receives a reference to the webhookRepo interface and stores it in the WebhookService structure
+// Params:
+// (webhookRepo): Reference to a webhookRepo interface
+// Return:
+// (*WebhookService): Reference to the WebhookService object
+func NewWebhookService(webhookRepo interfaces.IWebhookRepository) *WebhookService {
+	return &WebhookService{webhookRepo: webhookRepo, client: &http.Client{Timeout: 10 * time.Second}, maxAttempts: 5, backoff: 2 * time.Second}
+}
+
+// WithOrg: Returns a copy of the service that only sees the webhooks of an organization
+// Params:
+// (orgID): Id of the organization
+// Return:
+// (interfaces.IWebhookService): Scoped service
+func (s *WebhookService) WithOrg(orgID int64) interfaces.IWebhookService {
+	scoped := *s
+	scoped.webhookRepo = s.webhookRepo.WithOrg(orgID)
+	return &scoped
+}
+
+// Publish: Sends the event, in background, to every webhook of the organization of its domain subscribed to its type
+// Params:
+// (event): Reference to the event to be sent
+func (s *WebhookService) Publish(event *models.Event) {
+	if event.Domain == nil {
+		return
+	}
+	webhooks, err := s.webhookRepo.WithOrg(event.Domain.OrgId).GetAll()
+	if err != nil {
+		return
+	}
+	payload, jsonError := json.Marshal(event)
+	if jsonError != nil {
+		return
+	}
+	for _, webhook := range webhooks {
+		if matchesEvent(webhook.Events, event.Type) {
+			go s.deliver(webhook, event.Type, payload)
+		}
+	}
+}
+
+// deliver: Posts the payload to the webhook, retrying with an exponential back-off until it succeeds or the attempts run out.
+// Every attempt is stored in the delivery log
+// Params:
+// (webhook): Reference to the webhook
+// (eventType): Type of the event being delivered
+// (payload): JSON body of the request
+func (s *WebhookService) deliver(webhook *models.Webhook, eventType string, payload []byte) {
+	wait := s.backoff
+	for attempt := 1; attempt <= s.maxAttempts; attempt++ {
+		delivery := &models.Delivery{WebhookId: webhook.Id, EventType: eventType, Attempt: attempt}
+		statusCode, err := s.post(webhook, eventType, payload)
+		delivery.StatusCode = statusCode
+		delivery.DeliveredAt = time.Now().Unix()
+		if err != nil {
+			delivery.Error = err.Error()
+		} else {
+			delivery.Success = true
+		}
+		s.webhookRepo.SaveDelivery(delivery)
+		if delivery.Success || attempt == s.maxAttempts {
+			return
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// post: Makes a single signed request to the webhook url
+// Params:
+// (webhook): Reference to the webhook
+// (eventType): Type of the event being delivered
+// (payload): JSON body of the request
+// Return:
+// (int): Status code of the response, 0 if there was no response
+// (error): Error if the request fails or the response is not 2xx
+func (s *WebhookService) post(webhook *models.Webhook, eventType string, payload []byte) (int, error) {
+	req, err := http.NewRequest("POST", webhook.Url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", eventType)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignPayload(webhook.Secret, payload))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("Unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignPayload: Computes the HMAC-SHA256 signature of a payload
+// Params:
+// (secret): Secret of the webhook
+// (payload): Body to be signed
+// Return:
+// (string): Hex encoded signature
+func SignPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateWebhook: Validates and stores a new webhook subscription
+// Params:
+// (body): JSON body with the url, secret and events of the webhook
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *WebhookService) CreateWebhook(body []byte) ([]byte, error) {
+	var webhook *models.Webhook
+	if err := json.Unmarshal(body, &webhook); err != nil || webhook == nil {
+		return nil, errors.New("Invalid body")
+	}
+	parsedUrl, urlErr := url.Parse(webhook.Url)
+	if urlErr != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return nil, errors.New("Invalid webhook url")
+	}
+	if webhook.Secret == "" {
+		return nil, errors.New("Secret is required")
+	}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+	for _, eventType := range webhook.Events {
+		if isEventType(eventType) == false {
+			return nil, fmt.Errorf("Unknown event %s", eventType)
+		}
+	}
+	webhook.CreatedAt = time.Now().Unix()
+	id, saveErr := s.webhookRepo.Save(webhook)
+	if saveErr != nil {
+		return nil, saveErr
+	}
+	webhook.Id = id
+	webhook.Secret = ""
+	jsonBody, jsonError := json.Marshal(webhook)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// GetWebhooks: Returns a JSON object with the webhook subscriptions, without their secrets
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *WebhookService) GetWebhooks() ([]byte, error) {
+	webhooks, err := s.webhookRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": webhooks})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// DeleteWebhook: Removes a webhook subscription
+// Params:
+// (ID): Id of the webhook
+// Return:
+// (error): Error if the process fails
+func (s *WebhookService) DeleteWebhook(ID int64) error {
+	if _, err := s.webhookRepo.FindByID(ID); err != nil {
+		return errors.New("Webhook not found")
+	}
+	return s.webhookRepo.Delete(ID)
+}
+
+// GetDeliveries: Returns a JSON object with the latest delivery attempts of a webhook
+// Params:
+// (ID): Id of the webhook
+// Return:
+// ([]byte): JSON object
+// (error): Error if the process fails
+func (s *WebhookService) GetDeliveries(ID int64) ([]byte, error) {
+	if _, err := s.webhookRepo.FindByID(ID); err != nil {
+		return nil, errors.New("Webhook not found")
+	}
+	deliveries, err := s.webhookRepo.FindDeliveries(ID, 100)
+	if err != nil {
+		return nil, err
+	}
+	jsonBody, jsonError := json.Marshal(map[string]interface{}{"items": deliveries})
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	return jsonBody, nil
+}
+
+// matchesEvent: Auxiliary function that checks if a subscription listens to an event type.
+// A subscription without events listens to all of them
+// Params:
+// (events): Event types of the subscription
+// (eventType): Type of the event
+// Return:
+// (bool): True if the subscription matches. False if not
+func matchesEvent(events []string, eventType string) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, subscribed := range events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// isEventType: Auxiliary function that checks if a string is a known event type
+// Params:
+// (eventType): Type to be checked
+// Return:
+// (bool): True if the type exists. False if not
+func isEventType(eventType string) bool {
+	for _, known := range models.EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JonatanOrdonez/tr-backend/metrics"
+	"github.com/JonatanOrdonez/tr-backend/models"
+	"github.com/likexian/whois-go"
+	whoisparser "github.com/likexian/whois-parser-go"
+	"golang.org/x/net/publicsuffix"
+)
+
+// whoisDateLayouts: Date layouts used by the registries, tried in order
+var whoisDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006.01.02 15:04:05",
+	"2006.01.02",
+	"2006/01/02",
+	"02-Jan-2006",
+	"02-Jan-2006 15:04:05 MST",
+	"02.01.2006 15:04:05",
+	"02.01.2006",
+	"02/01/2006",
+	"January 2 2006",
+	"Mon Jan 2 15:04:05 MST 2006",
+}
+
+// WhoisCollector: Structure used to store the time during which a registration is reused
+type WhoisCollector struct {
+	ttl time.Duration
+}
+
+// NewWhoisCollector: Receives the time to live of the registrations and stores it in the WhoisCollector structure
+// Params:
+// (ttl): Time during which a collected registration is reused instead of querying WHOIS again
+// Return:
+// (*WhoisCollector): Reference to the WhoisCollector object
+func NewWhoisCollector(ttl time.Duration) *WhoisCollector {
+	return &WhoisCollector{ttl: ttl}
+}
+
+// Collect: Queries the WHOIS registration of the registrable domain of a host.
+// The cached registration is returned while it is younger than the ttl, unless it expires within the ttl,
+// so that renewals of domains about to lapse show up in the next scan
+// Params:
+// (host): Host to be queried, such as www.example.com
+// (cached): Reference to the registration of the previous scan, nil if there was none
+// Return:
+// (*models.Registration): Reference to the registration
+// (error): Error if the WHOIS query or its parsing fails
+func (c *WhoisCollector) Collect(host string, cached *models.Registration) (*models.Registration, error) {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(hostname(host)))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if cached != nil && cached.Domain == domain && now.Sub(time.Unix(cached.CheckedAt, 0)) < c.ttl {
+		if cached.ExpiresAt == 0 || time.Unix(cached.ExpiresAt, 0).After(now.Add(c.ttl)) {
+			return cached, nil
+		}
+	}
+	start := time.Now()
+	rawData, err := whois.Whois(domain)
+	metrics.ObserveWhois("domain", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	info, err := whoisparser.Parse(rawData)
+	if err != nil {
+		return nil, err
+	}
+	if info.Domain == nil {
+		return nil, errors.New("WHOIS response has no domain data")
+	}
+	registration := &models.Registration{Domain: domain, NameServers: []string{}, Status: []string{}, CheckedAt: now.Unix()}
+	if info.Registrar != nil {
+		registration.Registrar = info.Registrar.Name
+		if registration.Registrar == "" {
+			registration.Registrar = info.Registrar.Organization
+		}
+	}
+	registration.CreatedAt = parseWhoisDate(info.Domain.CreatedDate)
+	registration.ExpiresAt = parseWhoisDate(info.Domain.ExpirationDate)
+	for _, nameServer := range info.Domain.NameServers {
+		registration.NameServers = append(registration.NameServers, strings.TrimSuffix(strings.ToLower(nameServer), "."))
+	}
+	registration.Status = append(registration.Status, info.Domain.Status...)
+	return registration, nil
+}
+
+// parseWhoisDate: Auxiliary function that converts a WHOIS date into unix time
+// Params:
+// (value): Date as written by the registry
+// Return:
+// (int64): Unix time, 0 if the date is empty or has an unknown layout
+func parseWhoisDate(value string) int64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	for _, layout := range whoisDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Unix()
+		}
+	}
+	return 0
+}
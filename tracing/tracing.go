@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/JonatanOrdonez/tr-backend/metrics"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlphttp"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName: Name of the tracer that creates every span of the service
+const instrumentationName = "github.com/JonatanOrdonez/tr-backend"
+
+// contextKey: Key of the user value that stores the context of the request span
+const contextKey = "traceContext"
+
+// Setup: Installs the global tracer provider and the W3C trace context propagator
+// Params:
+// (exporter): otlp, stdout, or none to keep the spans disabled
+// (endpoint): URL of the OTLP/HTTP collector, such as http://localhost:4318, only used by the otlp exporter
+// (serviceName): Name of the service in the spans
+// Return:
+// (func(context.Context) error): Function that flushes the pending spans and stops the exporter
+// (error): Error if the exporter is unknown or cannot be created
+func Setup(exporter string, endpoint string, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	var spanExporter sdktrace.SpanExporter
+	switch exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		stdoutExporter, err := stdout.NewExporter(stdout.WithWriter(os.Stdout), stdout.WithoutMetricExport())
+		if err != nil {
+			return nil, err
+		}
+		spanExporter = stdoutExporter
+	case "otlp":
+		collector, err := url.Parse(endpoint)
+		if err != nil || collector.Host == "" {
+			return nil, errors.New("Invalid OTLP endpoint, it must be a URL such as http://localhost:4318")
+		}
+		options := []otlphttp.Option{otlphttp.WithEndpoint(collector.Host)}
+		if collector.Scheme == "http" {
+			options = append(options, otlphttp.WithInsecure())
+		}
+		if collector.Path != "" && collector.Path != "/" {
+			options = append(options, otlphttp.WithTracesURLPath(collector.Path))
+		}
+		otlpExporter, err := otlp.NewExporter(context.Background(), otlphttp.NewDriver(options...))
+		if err != nil {
+			return nil, err
+		}
+		spanExporter = otlpExporter
+	default:
+		return nil, fmt.Errorf("Unknown traces exporter %s", exporter)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start: Starts a span, child of the span of the context if it has one
+// Params:
+// (ctx): Parent context, context.Background() starts a new trace
+// (name): Name of the span
+// (attributes): Attributes of the span
+// Return:
+// (context.Context): Context of the span, passed to the nested calls
+// (trace.Span): Span, ended with End
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End: Ends a span and marks it as failed if there was an error
+// Params:
+// (span): Span started with Start
+// (err): Error of the traced call, nil if it succeeded
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Middleware: Starts a server span for every request, child of the traceparent header when the caller sends one.
+// The span is named after the route stored by metrics.Route once the router has handled the request
+// Params:
+// (next): Handler of the request
+// Return:
+// (fasthttp.RequestHandler): Wrapped handler
+func Middleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		parent := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier{header: &ctx.Request.Header})
+		method := string(ctx.Method())
+		spanCtx, span := otel.Tracer(instrumentationName).Start(parent, "HTTP "+method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			semconv.HTTPMethodKey.String(method),
+			semconv.HTTPTargetKey.String(string(ctx.RequestURI())),
+		))
+		ctx.SetUserValue(contextKey, spanCtx)
+		next(ctx)
+		route := metrics.RouteOf(ctx)
+		status := ctx.Response.StatusCode()
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRouteKey.String(route), semconv.HTTPStatusCodeKey.Int(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, fasthttp.StatusMessage(status))
+		}
+		span.End()
+	}
+}
+
+// Context: Returns the context of the request span, used as the parent of the spans of the services
+// Params:
+// (ctx): Request reference
+// Return:
+// (context.Context): Context of the span, context.Background() if the request was not traced
+func Context(ctx *fasthttp.RequestCtx) context.Context {
+	spanCtx, ok := ctx.UserValue(contextKey).(context.Context)
+	if ok == false {
+		return context.Background()
+	}
+	return spanCtx
+}
+
+// headerCarrier: Adapter that lets the propagator read and write the fasthttp request headers
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+// Get: Returns the value of a header
+func (c headerCarrier) Get(key string) string {
+	return string(c.header.Peek(key))
+}
+
+// Set: Sets the value of a header
+func (c headerCarrier) Set(key string, value string) {
+	c.header.Set(key, value)
+}
+
+// Keys: Returns the names of the headers
+func (c headerCarrier) Keys() []string {
+	keys := []string{}
+	c.header.VisitAll(func(key []byte, value []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}